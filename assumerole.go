@@ -0,0 +1,31 @@
+package secretsmanager
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// WithAssumeRole makes the default AWS clients assume the given role, for example to read secrets
+// that live in a central security account. The role is assumed with the credentials of the AWS
+// config, and the temporary credentials are cached and renewed before they expire. externalID is
+// passed to AssumeRole if it is not empty.
+func WithAssumeRole(roleARN, externalID string) Option {
+	return func(s *SecretsManager) {
+		s.assumeRoleARN = roleARN
+		s.assumeRoleExternalID = externalID
+	}
+}
+
+// assumeRole replaces the credentials of cfg with those of the role set by WithAssumeRole, if any.
+func (s *SecretsManager) assumeRole(cfg *aws.Config) {
+	if s.assumeRoleARN == "" {
+		return
+	}
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(*cfg), s.assumeRoleARN, func(o *stscreds.AssumeRoleOptions) {
+		if s.assumeRoleExternalID != "" {
+			o.ExternalID = &s.assumeRoleExternalID
+		}
+	})
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+}
@@ -0,0 +1,76 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// assumeRoleHTTPClient answers AssumeRole and GetSecretValue requests.
+type assumeRoleHTTPClient struct {
+	assumeRoleForm url.Values
+	authorization  string
+}
+
+func (c *assumeRoleHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	var contentType, body string
+	if strings.HasPrefix(req.URL.Host, "sts.") {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if c.assumeRoleForm, err = url.ParseQuery(string(b)); err != nil {
+			return nil, err
+		}
+		contentType = "text/xml"
+		body = `<AssumeRoleResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><AssumeRoleResult>
+<Credentials><AccessKeyId>ASIAASSUMED</AccessKeyId><SecretAccessKey>SECRET</SecretAccessKey>
+<SessionToken>TOKEN</SessionToken><Expiration>2099-01-01T00:00:00Z</Expiration></Credentials>
+</AssumeRoleResult></AssumeRoleResponse>`
+	} else {
+		c.authorization = req.Header.Get("Authorization")
+		contentType = "application/x-amz-json-1.1"
+		body = `{"Name":"test-secret","SecretString":"{\"DB_PASSWORD\":\"password\"}","VersionId":"v1"}`
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{contentType}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestSecretsManager_WithAssumeRole(t *testing.T) {
+	httpClient := &assumeRoleHTTPClient{}
+	cfg := aws.Config{
+		Region:     "us-test-1",
+		HTTPClient: httpClient,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKIASOURCE", SecretAccessKey: "SECRET"}, nil
+		}),
+	}
+	roleARN := "arn:aws:iam::123456789012:role/secrets-reader"
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithAWSConfig(cfg),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithAssumeRole(roleARN, "external-id"),
+	)
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	// The secret is fetched with the credentials of the assumed role.
+	require.Equal(t, "AssumeRole", httpClient.assumeRoleForm.Get("Action"))
+	require.Equal(t, roleARN, httpClient.assumeRoleForm.Get("RoleArn"))
+	require.Equal(t, "external-id", httpClient.assumeRoleForm.Get("ExternalId"))
+	require.Contains(t, httpClient.authorization, "Credential=ASIAASSUMED/")
+}
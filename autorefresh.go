@@ -0,0 +1,32 @@
+package secretsmanager
+
+import (
+	"context"
+	"time"
+)
+
+// StartAutoRefresh starts a background goroutine that refreshes the whole secret every interval,
+// until ctx is done. With an interval below the cache TTL, reads never wait for AWS, and rotations
+// are picked up within interval even when there is no traffic. A failed refresh is logged and keeps
+// the cached values; they are still refreshed on read once they expire. With WithLeaderCheck, only
+// the leader refreshes on every interval; followers only refresh once a new version is current.
+func (s *SecretsManager) StartAutoRefresh(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if !s.shouldPoll() {
+					s.refreshIfNewVersion(ctx, interval)
+					continue
+				}
+				if err := s.refresh(ctx); err != nil {
+					s.log().Error(ctx, "auto refresh failed", "secret", s.secretName, "error", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,36 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_StartAutoRefresh(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"initialPassword"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	secretsManager.StartAutoRefresh(ctx, 20*time.Millisecond)
+
+	// The rotation is picked up without any reads, despite the long TTL.
+	smMock.secretValue.Store(`{"DB_PASSWORD":"newPassword"}`)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&smMock.callCount) >= 2
+	}, time.Second, 10*time.Millisecond)
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "newPassword", val)
+
+	// No refreshes happen once ctx is done.
+	cancel()
+	time.Sleep(30 * time.Millisecond)
+	calls := atomic.LoadInt32(&smMock.callCount)
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, calls, atomic.LoadInt32(&smMock.callCount))
+}
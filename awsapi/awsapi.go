@@ -0,0 +1,61 @@
+// Package awsapi defines the narrow interfaces of the AWS Secrets Manager and KMS clients used by
+// the secretsmanager package. The interfaces are stable: methods are only ever added as new
+// interfaces, so consumers can build their own fakes against them. The default clients of the
+// AWS SDK implement all of them. The package only depends on the Secrets Manager and KMS SDK
+// modules.
+package awsapi
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// Client defines the subset of methods needed from the AWS Secrets Manager client.
+type Client interface {
+	GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// WriterClient is implemented by Secrets Manager clients that can write secret values.
+type WriterClient interface {
+	PutSecretValue(ctx context.Context, input *secretsmanager.PutSecretValueInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.PutSecretValueOutput, error)
+}
+
+// SecretDescriber is implemented by Secrets Manager clients that can describe secrets.
+type SecretDescriber interface {
+	DescribeSecret(ctx context.Context, input *secretsmanager.DescribeSecretInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.DescribeSecretOutput, error)
+}
+
+// LifecycleClient is implemented by Secrets Manager clients that can create and delete secrets.
+type LifecycleClient interface {
+	CreateSecret(ctx context.Context, input *secretsmanager.CreateSecretInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.CreateSecretOutput, error)
+	DeleteSecret(ctx context.Context, input *secretsmanager.DeleteSecretInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.DeleteSecretOutput, error)
+}
+
+// KMSClient defines the subset of methods needed from the AWS KMS client.
+type KMSClient interface {
+	Encrypt(ctx context.Context, input *kms.EncryptInput, opts ...func(*kms.Options)) (*kms.EncryptOutput, error)
+	Decrypt(ctx context.Context, input *kms.DecryptInput, opts ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSKeyDescriber is implemented by KMS clients that can describe keys.
+type KMSKeyDescriber interface {
+	DescribeKey(ctx context.Context, input *kms.DescribeKeyInput, opts ...func(*kms.Options)) (*kms.DescribeKeyOutput, error)
+}
+
+// DataKeyGenerator is implemented by KMS clients that can generate data keys.
+type DataKeyGenerator interface {
+	GenerateDataKey(ctx context.Context, input *kms.GenerateDataKeyInput, opts ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+}
+
+// The default clients implement every interface.
+var (
+	_ Client           = (*secretsmanager.Client)(nil)
+	_ WriterClient     = (*secretsmanager.Client)(nil)
+	_ SecretDescriber  = (*secretsmanager.Client)(nil)
+	_ LifecycleClient  = (*secretsmanager.Client)(nil)
+	_ KMSClient        = (*kms.Client)(nil)
+	_ KMSKeyDescriber  = (*kms.Client)(nil)
+	_ DataKeyGenerator = (*kms.Client)(nil)
+)
@@ -0,0 +1,44 @@
+package secretsmanager
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+)
+
+// WithAWSConfig builds the default AWS clients from cfg instead of loading the default AWS config,
+// to reuse the credentials, HTTP client and retryer the application already set up. The region
+// passed to NewSecretsManager overrides the region of cfg, unless it is empty. With
+// WithClientLogMode, the SDK logs to the logger of the SecretsManager rather than that of cfg.
+func WithAWSConfig(cfg aws.Config) Option {
+	return func(s *SecretsManager) {
+		cfg = cfg.Copy()
+		s.awsConfig = &cfg
+		if s.region == "" {
+			s.region = cfg.Region
+		}
+	}
+}
+
+// loadAWSConfig returns the AWS config to build the default clients from.
+func (s *SecretsManager) loadAWSConfig(ctx context.Context) (aws.Config, error) {
+	if s.awsConfig == nil {
+		opts := []func(*config.LoadOptions) error{config.WithRegion(s.region), config.WithClientLogMode(s.clientLogMode)}
+		if s.logger != nil && s.clientLogMode != 0 {
+			opts = append(opts, config.WithLogger(sdkLogger{s: s}))
+		}
+		return config.LoadDefaultConfig(ctx, opts...)
+	}
+	cfg := s.awsConfig.Copy()
+	if s.region != "" {
+		cfg.Region = s.region
+	}
+	if s.clientLogMode != 0 {
+		cfg.ClientLogMode = s.clientLogMode
+		if s.logger != nil {
+			cfg.Logger = sdkLogger{s: s}
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,103 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHTTPClient answers every request with a GetSecretValue response and records the hosts.
+type recordingHTTPClient struct {
+	hosts []string
+}
+
+func (c *recordingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.hosts = append(c.hosts, req.URL.Host)
+	body := `{"Name":"test-secret","SecretString":"{\"DB_PASSWORD\":\"password\"}","VersionId":"v1"}`
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.1"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestSecretsManager_WithAWSConfig(t *testing.T) {
+	httpClient := &recordingHTTPClient{}
+	cfg := aws.Config{
+		Region:     "eu-test-1",
+		HTTPClient: httpClient,
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		}),
+	}
+
+	// The region is taken from the config, and the default Secrets Manager client uses its HTTP client.
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithAWSConfig(cfg),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+	require.Equal(t, []string{"secretsmanager.eu-test-1.amazonaws.com"}, httpClient.hosts)
+}
+
+func TestNewSecretsManager_SkipsAWSConfigWithInjectedClients(t *testing.T) {
+	// Loading the default AWS config fails for a missing profile.
+	t.Setenv("AWS_PROFILE", "does-not-exist")
+	_, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "")
+	require.Error(t, err)
+
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.NoError(t, err)
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	// Without KMS encryption of the cache, no KMS client is needed.
+	_, err = secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithoutCacheEncryption(),
+	)
+	require.NoError(t, err)
+}
+
+func TestSecretsManager_WithClientLogMode(t *testing.T) {
+	cfg := aws.Config{
+		Region:     "eu-test-1",
+		HTTPClient: &recordingHTTPClient{},
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		}),
+	}
+	var logs syncBuffer
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithAWSConfig(cfg),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithClientLogMode(aws.LogRequest),
+		secretsmanagerWrapper.WithSlogLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+	)
+	require.NoError(t, err)
+
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+
+	// The SDK request log goes to the wrapper's logger.
+	require.Contains(t, logs.String(), "source=aws-sdk")
+	require.Contains(t, logs.String(), "secretsmanager.GetSecretValue")
+}
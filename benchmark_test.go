@@ -0,0 +1,109 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+)
+
+// newSecretsManagerForBenchmark creates a SecretsManager for a secret with ten keys.
+func newSecretsManagerForBenchmark(b *testing.B, cacheTTL time.Duration) (*secretsmanagerWrapper.SecretsManager, *mockSecretsManagerClient) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"K0":"v0","K1":"v1","K2":"v2","K3":"v3","K4":"v4","K5":"v5","K6":"v6","K7":"v7","K8":"v8","K9":"v9"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(cacheTTL),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return secretsManager, smMock
+}
+
+func BenchmarkGet_Hit(b *testing.B) {
+	secretsManager, _ := newSecretsManagerForBenchmark(b, time.Hour)
+	ctx := context.Background()
+	if _, err := secretsManager.GetWithContext(ctx, "K0"); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := secretsManager.GetWithContext(ctx, "K0"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGet_Miss(b *testing.B) {
+	// A zero TTL makes every read refresh the whole secret.
+	secretsManager, _ := newSecretsManagerForBenchmark(b, 0)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := secretsManager.GetWithContext(ctx, "K0"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetAll(b *testing.B) {
+	secretsManager, _ := newSecretsManagerForBenchmark(b, time.Hour)
+	ctx := context.Background()
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := secretsManager.GetAll(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWatch_Tick(b *testing.B) {
+	secretsManager, smMock := newSecretsManagerForBenchmark(b, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{})
+	secretsManager.Watch(ctx, "K0", 100*time.Microsecond, func(string) { changed <- struct{}{} })
+	// Let the initial fetch of the watcher happen.
+	time.Sleep(10 * time.Millisecond)
+
+	// Each iteration rotates the secret and waits for the watcher to report it, so this measures
+	// the ticks it takes to notice a rotation.
+	b.ReportAllocs()
+	i := 0
+	for b.Loop() {
+		i++
+		smMock.secretValue.Store(fmt.Sprintf(`{"K0":"v%d"}`, i))
+		<-changed
+	}
+}
+
+func BenchmarkGet_HitLocalCipher(b *testing.B) {
+	c, err := secretsmanagerWrapper.NewAESGCMCipher(make([]byte, 32))
+	if err != nil {
+		b.Fatal(err)
+	}
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"K0":"v0"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithCacheCipher(c),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+	)
+	if err != nil {
+		b.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := secretsManager.GetWithContext(ctx, "K0"); err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := secretsManager.GetWithContext(ctx, "K0"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// WithBinarySecrets enables reading secrets stored as SecretBinary rather than SecretString.
+// If base64Encoded is true, the binary payload is base64-decoded first. Payloads containing a
+// JSON object are cached as key–value pairs like string secrets; the raw bytes are available
+// through GetBinary either way.
+func WithBinarySecrets(base64Encoded bool) Option {
+	return func(s *SecretsManager) {
+		s.binarySecrets = true
+		s.base64Binary = base64Encoded
+	}
+}
+
+// decodeBinarySecret decodes a SecretBinary payload.
+func (s *SecretsManager) decodeBinarySecret(data []byte) (*fetchedSecret, error) {
+	if s.base64Binary {
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+		n, err := base64.StdEncoding.Decode(decoded, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to base64-decode binary secret: %w", err)
+		}
+		data = decoded[:n]
+	}
+
+	secret := &fetchedSecret{values: map[string]string{}, binary: data}
+	// The raw bytes are kept as they are; only the JSON values are read from the sanitized payload.
+	if payload, _ := sanitizePayload(data); json.Valid(payload) {
+		if err := s.checkPayload(payload); err != nil {
+			return nil, err
+		}
+		// Payloads that are JSON, but not a JSON object or array of objects, are kept as raw bytes only.
+		if values, err := parseSecret(payload); err == nil {
+			secret.values = values
+		}
+	}
+	return secret, nil
+}
+
+// GetBinary retrieves the raw payload of a binary secret. It requires WithBinarySecrets.
+// It refreshes the secret from AWS if the cache is expired.
+func (s *SecretsManager) GetBinary(ctx context.Context) ([]byte, error) {
+	if err := s.checkAccess(ctx, ""); err != nil {
+		return nil, err
+	}
+	s.cacheLock.RLock()
+	cs := s.binary
+	s.cacheLock.RUnlock()
+	if cs.encryptedValue == "" || !s.isFresh(cs) {
+		if err := s.refresh(ctx); err != nil {
+			return nil, err
+		}
+		s.cacheLock.RLock()
+		cs = s.binary
+		s.cacheLock.RUnlock()
+	}
+	if cs.encryptedValue == "" {
+		return nil, fmt.Errorf("secret %q has no binary value", s.secretName)
+	}
+
+	plaintext, err := s.cacheCipher().Decrypt(ctx, cs.encryptedValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt cached binary value: %w", err)
+	}
+	return []byte(plaintext), nil
+}
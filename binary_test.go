@@ -0,0 +1,65 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"encoding/base64"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// newBinarySecretsManagerForTest creates a SecretsManager that reads binary secrets.
+func newBinarySecretsManagerForTest(t *testing.T, sm secretsmanagerWrapper.Client, base64Encoded bool) *secretsmanagerWrapper.SecretsManager {
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(sm),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+		secretsmanagerWrapper.WithBinarySecrets(base64Encoded),
+	)
+	require.NoError(t, err)
+	return secretsManager
+}
+
+func TestSecretsManager_GetBinary(t *testing.T) {
+	keystore := []byte{0x00, 0xfe, 0xed, 0xfe, 0xed}
+	smMock := &mockSequencedSecretsManagerClient{responses: []mockSecretResponse{
+		{binary: keystore, version: "v1"},
+	}}
+	secretsManager := newBinarySecretsManagerForTest(t, smMock, false)
+
+	data, err := secretsManager.GetBinary(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, keystore, data)
+
+	// The raw bytes are served from the cache.
+	data, err = secretsManager.GetBinary(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, keystore, data)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_Get_BinaryJSON(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString([]byte(`{"DB_PASSWORD":"password"}`))
+	smMock := &mockSequencedSecretsManagerClient{responses: []mockSecretResponse{
+		{binary: []byte(encoded), version: "v1"},
+	}}
+	secretsManager := newBinarySecretsManagerForTest(t, smMock, true)
+
+	// A base64-encoded JSON payload is decoded into key–value pairs.
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	// Without WithBinarySecrets, binary secrets are rejected as before.
+	plain, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithRetry(1, time.Millisecond, time.Millisecond),
+	)
+	require.NoError(t, err)
+	_, err = plain.GetBinary(context.Background())
+	require.Error(t, err)
+}
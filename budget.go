@@ -0,0 +1,40 @@
+package secretsmanager
+
+import (
+	"context"
+	"time"
+)
+
+// Operation names an operation that can be given a latency budget.
+type Operation string
+
+// Operations with latency budgets.
+const (
+	// OperationGet is a read of a single key, including a refresh on a cache miss.
+	OperationGet Operation = "get"
+	// OperationRefresh is a fetch of the whole secret from AWS, including encrypting it for the cache.
+	OperationRefresh Operation = "refresh"
+)
+
+// WithLatencyBudget logs an Info message whenever op takes longer than budget, so that
+// regressions in the hot path show up in the logs before they hurt. It requires WithLogger.
+func WithLatencyBudget(op Operation, budget time.Duration) Option {
+	return func(s *SecretsManager) {
+		if s.budgets == nil {
+			s.budgets = make(map[Operation]time.Duration)
+		}
+		s.budgets[op] = budget
+	}
+}
+
+// checkBudget logs if op, which started at start, exceeded its latency budget.
+func (s *SecretsManager) checkBudget(ctx context.Context, op Operation, start time.Time) {
+	budget, ok := s.budgets[op]
+	if !ok {
+		return
+	}
+	if elapsed := time.Since(start); elapsed > budget {
+		s.log().Info(ctx, "latency budget exceeded", "secret", s.secretName, "operation", string(op),
+			"elapsed", elapsed, "budget", budget)
+	}
+}
@@ -0,0 +1,88 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidConfig is returned by Builder.Build and NewSecretsManager for a configuration that
+// cannot work, and by every constructor for invalid option values.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
+// Builder configures a SecretsManager step by step, as an alternative to the positional
+// arguments of NewSecretsManager. The zero value is not usable; create one with NewBuilder.
+type Builder struct {
+	region     string
+	secretName string
+	kmsKeyID   string
+	opts       []Option
+}
+
+// NewBuilder returns an empty Builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Region sets the AWS region. If it is not set, it is taken from the secret ARN, or else
+// resolved by the AWS SDK.
+func (b *Builder) Region(region string) *Builder {
+	b.region = region
+	return b
+}
+
+// Secret sets the name or ARN of the secret. It is required.
+func (b *Builder) Secret(secretName string) *Builder {
+	b.secretName = secretName
+	return b
+}
+
+// KMSKey sets the ID, ARN or alias of the KMS key that encrypts cached values. It is required
+// unless the cache is encrypted otherwise, e.g. with WithCacheCipher.
+func (b *Builder) KMSKey(kmsKeyID string) *Builder {
+	b.kmsKeyID = kmsKeyID
+	return b
+}
+
+// Logger sets the logger, as WithLogger does.
+func (b *Builder) Logger(logger Logger) *Builder {
+	return b.Options(WithLogger(logger))
+}
+
+// Options adds options, which are applied in order after those added before.
+func (b *Builder) Options(opts ...Option) *Builder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Build validates the configuration and creates the SecretsManager. All problems found are
+// reported at once, each wrapping ErrInvalidConfig. AWS calls made while building, such as
+// loading the AWS config or resolving a KMS alias, use ctx, so they can be bounded by the caller.
+func (b *Builder) Build(ctx context.Context) (*SecretsManager, error) {
+	s := newSecretsManager(b.region, b.secretName, b.kmsKeyID, b.opts)
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	if err := s.init(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// validate reports the problems of the configuration of s that would make it fail later on.
+func (s *SecretsManager) validate() error {
+	errs := append([]error(nil), s.optionErrs...)
+	if s.secretName == "" {
+		errs = append(errs, fmt.Errorf("%w: no secret name is set", ErrInvalidConfig))
+	}
+	if s.kmsKeyID == "" && s.usesKMSKey() {
+		errs = append(errs, fmt.Errorf("%w: no KMS key is set to encrypt cached values; set one or use WithCacheCipher or WithoutCacheEncryption", ErrInvalidConfig))
+	}
+	if s.cacheTTL <= 0 {
+		errs = append(errs, fmt.Errorf("%w: cache TTL must be positive, got %s", ErrInvalidConfig, s.cacheTTL))
+	}
+	if s.softTTL > s.cacheTTL {
+		errs = append(errs, fmt.Errorf("%w: soft TTL %s exceeds cache TTL %s", ErrInvalidConfig, s.softTTL, s.cacheTTL))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,61 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	var buf syncBuffer
+	logger := secretsmanagerWrapper.NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	secretsManager, err := secretsmanagerWrapper.NewBuilder().
+		Region("us-test-1").
+		Secret("test-secret").
+		KMSKey("test-kms-key").
+		Logger(logger).
+		Options(
+			secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+			secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		).
+		Build(context.Background())
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+	require.Contains(t, buf.String(), "secret refreshed")
+}
+
+func TestBuilder_Build_InvalidConfig(t *testing.T) {
+	// All problems are reported at once, before any AWS call.
+	_, err := secretsmanagerWrapper.NewBuilder().
+		Options(
+			secretsmanagerWrapper.WithCacheTTL(time.Minute),
+			secretsmanagerWrapper.WithSoftTTL(time.Hour),
+			secretsmanagerWrapper.WithCacheTTLJitter(1.5),
+		).
+		Build(context.Background())
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrInvalidConfig)
+	require.ErrorContains(t, err, "no secret name")
+	require.ErrorContains(t, err, "no KMS key")
+	require.ErrorContains(t, err, "soft TTL 1h0m0s exceeds cache TTL 1m0s")
+	require.ErrorContains(t, err, "cache TTL jitter must be in [0, 1), got 1.5")
+
+	// No KMS key is needed if the cache is not encrypted with KMS.
+	_, err = secretsmanagerWrapper.NewBuilder().
+		Secret("test-secret").
+		Options(
+			secretsmanagerWrapper.WithSecretsManagerClient(&mockSecretsManagerClient{}),
+			secretsmanagerWrapper.WithoutCacheEncryption(),
+		).
+		Build(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,40 @@
+package secretsmanager
+
+import (
+	"context"
+	"runtime"
+)
+
+// SecretBytes holds a secret value as bytes, whose backing array is zeroed once the SecretBytes
+// is garbage collected. It is a defense-in-depth measure where explicit wiping cannot be relied
+// upon everywhere; call Wipe to zero the value deterministically. The slice returned by Bytes
+// must not be used after the SecretBytes became unreachable; use runtime.KeepAlive if needed.
+type SecretBytes struct {
+	b []byte
+}
+
+// NewSecretBytes wraps b, which must not be used directly afterwards.
+func NewSecretBytes(b []byte) *SecretBytes {
+	sb := &SecretBytes{b: b}
+	runtime.AddCleanup(sb, func(b []byte) { clear(b) }, b)
+	return sb
+}
+
+// Bytes returns the secret value. It is all zeros after Wipe.
+func (sb *SecretBytes) Bytes() []byte {
+	return sb.b
+}
+
+// Wipe zeroes the secret value.
+func (sb *SecretBytes) Wipe() {
+	clear(sb.b)
+}
+
+// GetBytes is like GetWithContext, but returns the value as SecretBytes.
+func (s *SecretsManager) GetBytes(ctx context.Context, key string) (*SecretBytes, error) {
+	val, err := s.GetWithContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return NewSecretBytes([]byte(val)), nil
+}
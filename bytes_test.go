@@ -0,0 +1,35 @@
+package secretsmanager_test
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_GetBytes(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	sb, err := secretsManager.GetBytes(context.Background(), "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, []byte("password"), sb.Bytes())
+
+	sb.Wipe()
+	require.Equal(t, make([]byte, len("password")), sb.Bytes())
+}
+
+func TestSecretBytes_ZeroedWhenCollected(t *testing.T) {
+	backing := []byte("password")
+	secretsmanagerWrapper.NewSecretBytes(backing)
+
+	require.Eventually(t, func() bool {
+		runtime.GC()
+		return bytes.Equal(backing, make([]byte, len(backing)))
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,60 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCanaryCheckFailed is returned when a new version of the secret fails the check set with WithCanaryCheck.
+var ErrCanaryCheckFailed = errors.New("canary check failed")
+
+// CanaryCheck verifies the key–value pairs of a new secret version, e.g. by logging into the
+// database with them, before they are served.
+type CanaryCheck func(ctx context.Context, values map[string]string) error
+
+// WithCanaryCheck runs check whenever a refresh returns a payload that differs from the cached one,
+// i.e. after a rotation or a Put. Only if it passes is the new version cached, served and reported
+// to watchers. If it fails, the previous values are kept and served for another cache TTL, after
+// which the new version is checked again; the refresh fails with ErrCanaryCheckFailed.
+// The first fetch is not checked, as there is nothing to fall back to.
+func WithCanaryCheck(check CanaryCheck) Option {
+	return func(s *SecretsManager) {
+		s.canaryCheck = check
+	}
+}
+
+// checkCanary runs the canary check on secret if it differs from the cached version.
+func (s *SecretsManager) checkCanary(ctx context.Context, secret *fetchedSecret) error {
+	if s.canaryCheck == nil {
+		return nil
+	}
+	s.cacheLock.RLock()
+	previous := s.contentHash
+	s.cacheLock.RUnlock()
+	if previous == "" || previous == secret.contentHash() {
+		return nil
+	}
+	if err := s.canaryCheck(ctx, secret.values); err != nil {
+		s.extendCache()
+		return fmt.Errorf("%w: %w", ErrCanaryCheckFailed, err)
+	}
+	return nil
+}
+
+// extendCache marks all cached values as fetched now, so they are served for another cache TTL.
+func (s *SecretsManager) extendCache() {
+	s.cacheLock.Lock()
+	defer s.cacheLock.Unlock()
+	now := time.Now()
+	for k, cs := range s.cache {
+		cs.fetchedAt, cs.lastReadAt = now, now
+		s.cache[k] = cs
+	}
+	for _, cs := range []*cachedSecret{&s.keySet, &s.payload, &s.binary} {
+		if !cs.fetchedAt.IsZero() {
+			cs.fetchedAt, cs.lastReadAt = now, now
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_WithCanaryCheck(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"initialPassword"}`)
+	var healthy atomic.Bool
+	var checks atomic.Int32
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(30*time.Millisecond),
+		secretsmanagerWrapper.WithCanaryCheck(func(_ context.Context, values map[string]string) error {
+			checks.Add(1)
+			if !healthy.Load() {
+				return errors.New("cannot log in with " + values["DB_PASSWORD"])
+			}
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	// The first fetch is not checked.
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "initialPassword", val)
+	require.Equal(t, int32(0), checks.Load())
+
+	// A broken rotation is not served; the previous value is.
+	smMock.secretValue.Store(`{"DB_PASSWORD":"brokenPassword"}`)
+	time.Sleep(40 * time.Millisecond)
+	val, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "initialPassword", val)
+	require.Equal(t, int32(1), checks.Load())
+
+	// The previous value is kept for another TTL before the new version is checked again.
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), checks.Load())
+
+	healthy.Store(true)
+	time.Sleep(40 * time.Millisecond)
+	val, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "brokenPassword", val)
+	require.Equal(t, int32(2), checks.Load())
+}
+
+func TestSecretsManager_PutAll_WithCanaryCheck(t *testing.T) {
+	smMock := &mockSecretsByIDClient{secrets: map[string]string{"test-secret": `{"DB_PASSWORD":"oldPassword"}`}}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+		secretsmanagerWrapper.WithCanaryCheck(func(_ context.Context, values map[string]string) error {
+			if values["DB_PASSWORD"] == "" {
+				return errors.New("empty password")
+			}
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+
+	// A write that fails the check is reported, and the previous value is still served.
+	err = secretsManager.Put(context.Background(), "DB_PASSWORD", "")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrCanaryCheckFailed)
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "oldPassword", val)
+
+	require.NoError(t, secretsManager.Put(context.Background(), "DB_PASSWORD", "newPassword"))
+	val, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "newPassword", val)
+}
+
+func TestSecretsManager_PutAll_WithCanaryCheck_ConcurrentRefresh(t *testing.T) {
+	smMock := &mockSecretsByIDClient{secrets: map[string]string{"test-secret": `{"DB_PASSWORD":"oldPassword"}`}}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+		secretsmanagerWrapper.WithCanaryCheck(func(context.Context, map[string]string) error { return nil }),
+	)
+	require.NoError(t, err)
+
+	// Refreshes of other readers share the refresh of the write, so none of them can cache the
+	// previous version after the new one.
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				secretsManager.InvalidateAll()
+				_, _ = secretsManager.Get("DB_PASSWORD")
+			}
+		}()
+	}
+	require.NoError(t, secretsManager.Put(context.Background(), "DB_PASSWORD", "newPassword"))
+	cancel()
+	wg.Wait()
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "newPassword", val)
+}
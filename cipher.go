@@ -0,0 +1,235 @@
+package secretsmanager
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"maps"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// passphraseIterations is the PBKDF2-HMAC-SHA256 iteration count used by NewPassphraseCipher.
+const passphraseIterations = 600_000
+
+// CacheCipher encrypts the values held in the cache. The default cipher uses AWS KMS with the
+// key passed to NewSecretsManager; NewAESGCMCipher and NewPassphraseCipher encrypt locally.
+type CacheCipher interface {
+	// Encrypt returns a printable ciphertext of plaintext.
+	Encrypt(ctx context.Context, plaintext string) (string, error)
+	// Decrypt returns the plaintext of a ciphertext returned by Encrypt.
+	Decrypt(ctx context.Context, ciphertext string) (string, error)
+}
+
+// WithCacheCipher allows the cipher used to encrypt cached values to be set, e.g. to run without
+// KMS in air-gapped or development environments. The KMS key ID is then not used.
+func WithCacheCipher(c CacheCipher) Option {
+	return func(s *SecretsManager) {
+		s.cipher = c
+	}
+}
+
+// WithoutCacheEncryption caches values in plaintext in process memory, saving a KMS call per key
+// on every refresh and per read on every cache hit. Secrets Manager still encrypts secrets at
+// rest; only the in-process copy is unprotected. The KMS key ID is then not used.
+func WithoutCacheEncryption() Option {
+	return WithCacheCipher(plaintextCipher{})
+}
+
+// WithKMSDecryptKeys restricts decryption of cached values to the KMS key passed to
+// NewSecretsManager, falling back to the given candidate keys in order. Use it while migrating
+// to a new key, e.g. by repointing an alias, if decryption must name the key explicitly.
+// Without it, KMS identifies the key of each cached value from its ciphertext.
+func WithKMSDecryptKeys(keyIDs ...string) Option {
+	return func(s *SecretsManager) {
+		s.decryptKeyIDs = append(s.decryptKeyIDs, keyIDs...)
+	}
+}
+
+// WithKMSEncryptionContext passes encryptionContext to every KMS operation on cached values, for
+// audit logs and key policy conditions. KMS only decrypts a value with the context it was
+// encrypted with, so values are verified against the context on every read.
+func WithKMSEncryptionContext(encryptionContext map[string]string) Option {
+	return func(s *SecretsManager) {
+		s.encryptionContext = maps.Clone(encryptionContext)
+	}
+}
+
+// cacheCipher returns the cipher for cached values.
+func (s *SecretsManager) cacheCipher() CacheCipher {
+	var c CacheCipher = kmsCipher{s: s}
+	if s.cipher != nil {
+		c = s.cipher
+	}
+	if s.profilerLabels {
+		return labeledCipher{c: c, s: s}
+	}
+	return c
+}
+
+// usesKMSKey reports whether cached values are encrypted under the KMS key.
+func (s *SecretsManager) usesKMSKey() bool {
+	_, envelope := s.cipher.(envelopeCipher)
+	return s.cipher == nil || envelope
+}
+
+// batchEncrypter is implemented by ciphers that share setup, such as a KMS data key, between the
+// values of one refresh.
+type batchEncrypter interface {
+	// encrypter returns a CacheCipher that encrypts the values of one refresh.
+	encrypter(ctx context.Context) (CacheCipher, error)
+}
+
+// refreshCipher returns the cipher that encrypts the values of one refresh.
+func (s *SecretsManager) refreshCipher(ctx context.Context) (CacheCipher, error) {
+	c := s.cacheCipher()
+	if b, ok := c.(batchEncrypter); ok {
+		return b.encrypter(ctx)
+	}
+	return c, nil
+}
+
+// kmsCipher encrypts with the current KMS client and key of a SecretsManager.
+type kmsCipher struct {
+	s *SecretsManager
+}
+
+// Encrypt implements CacheCipher.
+func (c kmsCipher) Encrypt(ctx context.Context, plaintext string) (string, error) {
+	ctx, span := c.s.startSpan(ctx, "kms.Encrypt")
+	ciphertext, err := encryptValue(ctx, c.s.kmsAPI(), c.s.kmsKeyID, plaintext, c.s.encryptionContext)
+	endSpan(span, err)
+	return ciphertext, err
+}
+
+// Decrypt implements CacheCipher. With WithKMSDecryptKeys, the KMS key of the SecretsManager and
+// then the candidate keys are tried in turn; otherwise KMS identifies the key from the ciphertext.
+func (c kmsCipher) Decrypt(ctx context.Context, ciphertext string) (string, error) {
+	ctx, span := c.s.startSpan(ctx, "kms.Decrypt")
+	plaintext, err := c.decrypt(ctx, ciphertext)
+	endSpan(span, err)
+	return plaintext, err
+}
+
+// decrypt implements Decrypt.
+func (c kmsCipher) decrypt(ctx context.Context, ciphertext string) (string, error) {
+	if len(c.s.decryptKeyIDs) == 0 {
+		return decryptValue(ctx, c.s.kmsAPI(), "", ciphertext, c.s.encryptionContext)
+	}
+	var errs []error
+	for _, keyID := range append([]string{c.s.kmsKeyID}, c.s.decryptKeyIDs...) {
+		plaintext, err := decryptValue(ctx, c.s.kmsAPI(), keyID, ciphertext, c.s.encryptionContext)
+		if err == nil {
+			return plaintext, nil
+		}
+		var incorrectKey *types.IncorrectKeyException
+		if !errors.As(err, &incorrectKey) {
+			return "", err
+		}
+		errs = append(errs, err)
+	}
+	return "", errors.Join(errs...)
+}
+
+// aesGCMCipher encrypts locally with AES-GCM.
+type aesGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMCipher returns a CacheCipher that encrypts locally with AES-GCM under key,
+// which must be 16, 24 or 32 bytes long.
+func NewAESGCMCipher(key []byte) (CacheCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &aesGCMCipher{aead: aead}, nil
+}
+
+// NewPassphraseCipher returns a CacheCipher that encrypts locally with AES-256-GCM under a key
+// derived from passphrase with PBKDF2, e.g. a passphrase read from the OS keyring. The salt is
+// random, as cached values never outlive the process.
+func NewPassphraseCipher(passphrase string) (CacheCipher, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase is empty")
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, passphraseIterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	return NewAESGCMCipher(key)
+}
+
+// Encrypt implements CacheCipher. The ciphertext is the base64-encoded nonce followed by the sealed plaintext.
+func (c *aesGCMCipher) Encrypt(_ context.Context, plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(c.aead.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+// Decrypt implements CacheCipher. It works in a pooled buffer, so it only allocates the returned plaintext.
+func (c *aesGCMCipher) Decrypt(_ context.Context, ciphertext string) (string, error) {
+	bufp := decryptBuffers.Get().(*[]byte)
+	defer func() {
+		// Do not leave plaintext behind in the pool.
+		clear(*bufp)
+		decryptBuffers.Put(bufp)
+	}()
+
+	// The buffer holds the encoded ciphertext, followed by the decoded one.
+	size := len(ciphertext) + base64.StdEncoding.DecodedLen(len(ciphertext))
+	if cap(*bufp) < size {
+		*bufp = make([]byte, size)
+	}
+	buf := (*bufp)[:size]
+	src, dst := buf[:len(ciphertext)], buf[len(ciphertext):]
+	copy(src, ciphertext)
+	n, err := base64.StdEncoding.Decode(dst, src)
+	if err != nil {
+		return "", err
+	}
+	data := dst[:n]
+	if len(data) < c.aead.NonceSize() {
+		return "", errors.New("ciphertext is too short")
+	}
+	nonce, sealed := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(sealed[:0], nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// decryptBuffers pools the buffers of aesGCMCipher.Decrypt.
+var decryptBuffers = sync.Pool{
+	New: func() any { return new([]byte) },
+}
+
+// plaintextCipher is the CacheCipher of WithoutCacheEncryption, which leaves values unchanged.
+type plaintextCipher struct{}
+
+// Encrypt implements CacheCipher.
+func (plaintextCipher) Encrypt(_ context.Context, plaintext string) (string, error) {
+	return plaintext, nil
+}
+
+// Decrypt implements CacheCipher.
+func (plaintextCipher) Decrypt(_ context.Context, ciphertext string) (string, error) {
+	return ciphertext, nil
+}
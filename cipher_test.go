@@ -0,0 +1,114 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheCipher_RoundTrip(t *testing.T) {
+	aesCipher, err := secretsmanagerWrapper.NewAESGCMCipher(make([]byte, 32))
+	require.NoError(t, err)
+	passphraseCipher, err := secretsmanagerWrapper.NewPassphraseCipher("correct horse battery staple")
+	require.NoError(t, err)
+
+	for name, c := range map[string]secretsmanagerWrapper.CacheCipher{"aes-gcm": aesCipher, "passphrase": passphraseCipher} {
+		t.Run(name, func(t *testing.T) {
+			ciphertext, err := c.Encrypt(context.Background(), "password")
+			require.NoError(t, err)
+			require.NotContains(t, ciphertext, "password")
+
+			plaintext, err := c.Decrypt(context.Background(), ciphertext)
+			require.NoError(t, err)
+			require.Equal(t, "password", plaintext)
+
+			// Tampered ciphertexts are rejected.
+			tampered := []byte(ciphertext)
+			tampered[len(tampered)/2] ^= 'A' ^ 'B'
+			_, err = c.Decrypt(context.Background(), string(tampered))
+			require.Error(t, err)
+		})
+	}
+
+	_, err = secretsmanagerWrapper.NewAESGCMCipher([]byte("short"))
+	require.Error(t, err)
+	_, err = secretsmanagerWrapper.NewPassphraseCipher("")
+	require.Error(t, err)
+}
+
+func TestSecretsManager_WithCacheCipher(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	secretJSON, _ := json.Marshal(map[string]string{"DB_PASSWORD": "password"})
+	smMock.secretValue.Store(string(secretJSON))
+	c, err := secretsmanagerWrapper.NewAESGCMCipher(make([]byte, 16))
+	require.NoError(t, err)
+
+	// KMS is never called, so a failing KMS client does not matter.
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClientEncryptFailure{}),
+		secretsmanagerWrapper.WithCacheCipher(c),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+	)
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	// The cached value is decrypted with the same cipher.
+	val, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+}
+
+func TestSecretsManager_Get_HitAllocations(t *testing.T) {
+	c, err := secretsmanagerWrapper.NewAESGCMCipher(make([]byte, 32))
+	require.NoError(t, err)
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithCacheCipher(c),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+	_, err = secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	require.NoError(t, err)
+
+	// With local decryption, a cache hit only allocates the returned string.
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	})
+	require.LessOrEqual(t, allocs, 1.0)
+}
+
+func TestSecretsManager_WithoutCacheEncryption(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+
+	// KMS is never called, so a failing KMS client does not matter.
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClientEncryptFailure{}),
+		secretsmanagerWrapper.WithoutCacheEncryption(),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+	val, err := secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	// A cache hit returns the cached string as is.
+	allocs := testing.AllocsPerRun(100, func() {
+		val, _ = secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	})
+	require.Equal(t, "password", val)
+	require.Zero(t, allocs)
+}
@@ -0,0 +1,100 @@
+package secretsmanager
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// clientSet holds the AWS clients of a SecretsManager. A MultiSecretsManager shares one set between
+// its secrets, so rebuilding it, e.g. in ResetIdentity, takes effect for all of them.
+type clientSet struct {
+	mu sync.RWMutex
+
+	secretsManager Client
+	kms            KMSClient
+	// primary reads a replicated secret in its primary region, see WithPrimaryRegion.
+	primary Client
+
+	// defaultSecretsManager, defaultKMS and defaultPrimary record which clients were built from
+	// the AWS config rather than injected, and are rebuilt by ResetIdentity.
+	defaultSecretsManager bool
+	defaultKMS            bool
+	defaultPrimary        bool
+
+	// credentials are those of the default clients.
+	credentials aws.CredentialsProvider
+}
+
+// buildClients loads the AWS config and creates the AWS clients that were not injected.
+// If every client that is needed was injected, the AWS config is not loaded at all, as that may
+// reach out to the instance metadata service or fail outside of AWS, e.g. in tests.
+func (s *SecretsManager) buildClients(ctx context.Context) error {
+	if !s.needsDefaultClients() {
+		return nil
+	}
+
+	// Load AWS config.
+	cfg, err := s.loadAWSConfig(ctx)
+	if err != nil {
+		return err
+	}
+	s.assumeRole(&cfg)
+
+	c := s.clients
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.credentials = cfg.Credentials
+	if c.secretsManager == nil || c.defaultSecretsManager {
+		c.secretsManager = secretsmanager.NewFromConfig(cfg)
+		c.defaultSecretsManager = true
+	}
+	if c.kms == nil || c.defaultKMS {
+		c.kms = kms.NewFromConfig(cfg)
+		c.defaultKMS = true
+	}
+	if s.primaryRegion != "" && (c.primary == nil || c.defaultPrimary) {
+		c.primary = secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+			o.Region = s.primaryRegion
+		})
+		c.defaultPrimary = true
+	}
+	return nil
+}
+
+// needsDefaultClients reports whether any client that is needed was not injected.
+func (s *SecretsManager) needsDefaultClients() bool {
+	c := s.clients
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.secretsManager == nil || c.defaultSecretsManager ||
+		(s.usesKMSKey() && (c.kms == nil || c.defaultKMS)) ||
+		(s.primaryRegion != "" && (c.primary == nil || c.defaultPrimary))
+}
+
+// hasClients reports whether every client that is needed was injected or built already, as for
+// the secrets of a MultiSecretsManager after the first.
+func (s *SecretsManager) hasClients() bool {
+	c := s.clients
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.secretsManager != nil && (!s.usesKMSKey() || c.kms != nil) &&
+		(s.primaryRegion == "" || c.primary != nil)
+}
+
+// secretsManagerAPI returns the current Secrets Manager client.
+func (s *SecretsManager) secretsManagerAPI() Client {
+	s.clients.mu.RLock()
+	defer s.clients.mu.RUnlock()
+	return s.clients.secretsManager
+}
+
+// kmsAPI returns the current KMS client.
+func (s *SecretsManager) kmsAPI() KMSClient {
+	s.clients.mu.RLock()
+	defer s.clients.mu.RUnlock()
+	return s.clients.kms
+}
@@ -0,0 +1,38 @@
+package secretsmanager
+
+import (
+	"context"
+	"time"
+)
+
+// coldStartSlot serializes the fetches of all SecretsManagers within their cold-start window.
+var coldStartSlot = make(chan struct{}, 1)
+
+// WithColdStartProtection smooths the burst of fetches on a freshly started process. For window
+// after the SecretsManager is created, its fetches are serialized with those of every other
+// SecretsManager in the process that is still within its own window, and entries fetched in that
+// period stay fresh for at least window, even if the cache TTL is shorter.
+func WithColdStartProtection(window time.Duration) Option {
+	return func(s *SecretsManager) {
+		s.coldStartWindow = window
+	}
+}
+
+// inColdStart reports whether t falls within the cold-start window.
+func (s *SecretsManager) inColdStart(t time.Time) bool {
+	return s.coldStartWindow > 0 && t.Sub(s.startedAt) < s.coldStartWindow
+}
+
+// acquireColdStartSlot waits for the process-wide fetch slot within the cold-start window, and
+// returns a function that releases it.
+func (s *SecretsManager) acquireColdStartSlot(ctx context.Context) (func(), error) {
+	if !s.inColdStart(time.Now()) {
+		return func() {}, nil
+	}
+	select {
+	case coldStartSlot <- struct{}{}:
+		return func() { <-coldStartSlot }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
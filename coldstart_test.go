@@ -0,0 +1,69 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mockConcurrencyClient simulates a slow Secrets Manager client and records how many calls overlap.
+type mockConcurrencyClient struct {
+	callCount   int32
+	inFlight    int32
+	maxInFlight int32
+}
+
+func (m *mockConcurrencyClient) GetSecretValue(_ context.Context, _ *awsSecretsManager.GetSecretValueInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
+	atomic.AddInt32(&m.callCount, 1)
+	n := atomic.AddInt32(&m.inFlight, 1)
+	defer atomic.AddInt32(&m.inFlight, -1)
+	for {
+		maxInFlight := atomic.LoadInt32(&m.maxInFlight)
+		if n <= maxInFlight || atomic.CompareAndSwapInt32(&m.maxInFlight, maxInFlight, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return &awsSecretsManager.GetSecretValueOutput{SecretString: aws.String(`{"DB_PASSWORD":"password"}`)}, nil
+}
+
+func TestSecretsManager_WithColdStartProtection(t *testing.T) {
+	smMock := &mockConcurrencyClient{}
+	var managers []*secretsmanagerWrapper.SecretsManager
+	for range 3 {
+		sm, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+			secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+			secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+			secretsmanagerWrapper.WithCacheTTL(time.Millisecond),
+			secretsmanagerWrapper.WithColdStartProtection(time.Hour),
+		)
+		require.NoError(t, err)
+		managers = append(managers, sm)
+	}
+
+	// The first fetches of all managers are serialized.
+	var wg sync.WaitGroup
+	for _, sm := range managers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := sm.Get("DB_PASSWORD")
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.maxInFlight))
+
+	// Within the window, entries outlive the cache TTL.
+	time.Sleep(5 * time.Millisecond)
+	_, err := managers[0].Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(&smMock.callCount))
+}
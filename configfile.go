@@ -0,0 +1,152 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the document read by NewFromConfigFile.
+type fileConfig struct {
+	Region     string        `yaml:"region"`
+	SecretName string        `yaml:"secret_name"`
+	KMSKeyID   string        `yaml:"kms_key_id"`
+	CacheTTL   time.Duration `yaml:"cache_ttl"`
+	SoftTTL    time.Duration `yaml:"soft_ttl"`
+	Retry      *struct {
+		MaxAttempts  int           `yaml:"max_attempts"`
+		InitialDelay time.Duration `yaml:"initial_delay"`
+		MaxDelay     time.Duration `yaml:"max_delay"`
+	} `yaml:"retry"`
+	RequiredKeys []string    `yaml:"required_keys"`
+	Watch        []watchRule `yaml:"watch"`
+}
+
+// watchRule declares that changes to Key are handled by the watch action named Action.
+type watchRule struct {
+	Key      string        `yaml:"key"`
+	Interval time.Duration `yaml:"interval"`
+	Action   string        `yaml:"action"`
+}
+
+// WatchAction handles a change of a watched key to newVal.
+type WatchAction func(key, newVal string)
+
+// WithWatchAction registers action under name, so that watch rules in a config file can refer to it.
+func WithWatchAction(name string, action WatchAction) Option {
+	return func(s *SecretsManager) {
+		if s.watchActions == nil {
+			s.watchActions = make(map[string]WatchAction)
+		}
+		s.watchActions[name] = action
+	}
+}
+
+// NewFromConfigFile creates a new SecretsManager from a YAML or JSON config file, e.g.:
+//
+//	region: us-west-2
+//	secret_name: my-secret-id
+//	kms_key_id: my-kms-key-id
+//	cache_ttl: 30m
+//	soft_ttl: 20m
+//	retry:
+//	  max_attempts: 5
+//	  initial_delay: 200ms
+//	  max_delay: 5s
+//	required_keys: [DB_USER, DB_PASSWORD]
+//	watch:
+//	  - key: TLS_CERT
+//	    interval: 1m
+//	    action: reload-tls
+//
+// The configuration is validated as by NewSecretsManager, so kms_key_id may be left out if the
+// cache is encrypted otherwise, e.g. with WithCacheCipher. If required_keys is set, the secret is
+// fetched once and an error listing every missing key is returned if any of them is absent. Each
+// watch rule must name an action registered with WithWatchAction; the rules are started with
+// StartWatchRules. Options passed explicitly override the config file.
+func NewFromConfigFile(path string, opts ...Option) (*SecretsManager, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg fileConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if cfg.SecretName == "" {
+		return nil, fmt.Errorf("config file %s: secret_name is not set", path)
+	}
+
+	var fileOpts []Option
+	if cfg.CacheTTL > 0 {
+		fileOpts = append(fileOpts, WithCacheTTL(cfg.CacheTTL))
+	}
+	if cfg.SoftTTL > 0 {
+		fileOpts = append(fileOpts, WithSoftTTL(cfg.SoftTTL))
+	}
+	if cfg.Retry != nil {
+		fileOpts = append(fileOpts, WithRetry(cfg.Retry.MaxAttempts, cfg.Retry.InitialDelay, cfg.Retry.MaxDelay))
+	}
+
+	secretsManager, err := NewSecretsManager(cfg.Region, cfg.SecretName, cfg.KMSKeyID, append(fileOpts, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range cfg.Watch {
+		if _, ok := secretsManager.watchActions[rule.Action]; !ok {
+			return nil, fmt.Errorf("config file %s: watch rule for %s: unknown action %q", path, rule.Key, rule.Action)
+		}
+	}
+	secretsManager.watchRules = cfg.Watch
+
+	if len(cfg.RequiredKeys) > 0 {
+		if err := secretsManager.checkKeys(context.Background(), cfg.RequiredKeys); err != nil {
+			return nil, err
+		}
+	}
+	return secretsManager, nil
+}
+
+// StartWatchRules starts a watcher for each watch rule of the config file, running the rule's
+// action whenever the watched key changes. A rule without an interval polls once per cache TTL.
+// The watchers stop when ctx is done.
+func (s *SecretsManager) StartWatchRules(ctx context.Context) {
+	for _, rule := range s.watchRules {
+		action := s.watchActions[rule.Action]
+		interval := rule.Interval
+		if interval <= 0 {
+			interval = s.cacheTTL
+		}
+		key := rule.Key
+		s.Watch(ctx, key, interval, func(newVal string) {
+			action(key, newVal)
+		})
+	}
+}
+
+// checkKeys refreshes the secret and returns a *MissingKeysError listing all keys that are missing
+// from it.
+func (s *SecretsManager) checkKeys(ctx context.Context, keys []string) error {
+	if err := s.refresh(ctx); err != nil {
+		return err
+	}
+
+	s.cacheLock.RLock()
+	defer s.cacheLock.RUnlock()
+	var missing []string
+	for _, key := range keys {
+		if _, ok := s.cache[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return &MissingKeysError{SecretID: s.secretName, Keys: missing}
+	}
+	return nil
+}
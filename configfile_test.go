@@ -0,0 +1,164 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// writeConfigFile writes content to a file named name in a temporary directory and returns its path.
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestNewFromConfigFile(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"DB_USER": "admin", "DB_PASSWORD": "password"})
+	require.NoError(t, err)
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+
+	yamlPath := writeConfigFile(t, "secrets.yaml", `
+region: us-test-1
+secret_name: test-secret
+kms_key_id: test-kms-key
+cache_ttl: 30m
+required_keys: [DB_USER, DB_PASSWORD]
+`)
+	secretsManager, err := secretsmanagerWrapper.NewFromConfigFile(yamlPath,
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.NoError(t, err)
+
+	// The required keys were fetched at construction, so this is served from the cache.
+	val, err := secretsManager.Get("DB_USER")
+	require.NoError(t, err)
+	require.Equal(t, "admin", val)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+
+	// JSON documents are accepted as well.
+	jsonPath := writeConfigFile(t, "secrets.json", `{
+  "region": "us-test-1",
+  "secret_name": "test-secret",
+  "kms_key_id": "test-kms-key",
+  "required_keys": ["DB_USER", "API_KEY", "API_SECRET"]
+}`)
+	_, err = secretsmanagerWrapper.NewFromConfigFile(jsonPath,
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+	require.ErrorContains(t, err, "API_KEY, API_SECRET")
+}
+
+func TestNewFromConfigFile_Retry(t *testing.T) {
+	smMock := &mockSecretsManagerClient{err: errors.New("simulated SM error")}
+
+	path := writeConfigFile(t, "secrets.yaml", `
+secret_name: test-secret
+kms_key_id: test-kms-key
+retry:
+  max_attempts: 2
+  initial_delay: 1ms
+`)
+	secretsManager, err := secretsmanagerWrapper.NewFromConfigFile(path,
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.NoError(t, err)
+
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.Error(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestNewFromConfigFile_Invalid(t *testing.T) {
+	_, err := secretsmanagerWrapper.NewFromConfigFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+
+	path := writeConfigFile(t, "secrets.yaml", "kms_key_id: test-kms-key\n")
+	_, err = secretsmanagerWrapper.NewFromConfigFile(path)
+	require.ErrorContains(t, err, "secret_name")
+}
+
+func TestNewFromConfigFile_KMSKey(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	path := writeConfigFile(t, "secrets.yaml", "region: us-test-1\nsecret_name: test-secret\n")
+
+	// Without a KMS key, the cache must be encrypted otherwise.
+	_, err := secretsmanagerWrapper.NewFromConfigFile(path,
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrInvalidConfig)
+
+	secretsManager, err := secretsmanagerWrapper.NewFromConfigFile(path,
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithoutCacheEncryption(),
+	)
+	require.NoError(t, err)
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+}
+
+func TestNewFromConfigFile_WatchRules(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"TLS_CERT": "initialCert"})
+	require.NoError(t, err)
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+
+	path := writeConfigFile(t, "secrets.yaml", `
+secret_name: test-secret
+kms_key_id: test-kms-key
+cache_ttl: 20ms
+watch:
+  - key: TLS_CERT
+    interval: 20ms
+    action: reload-tls
+`)
+
+	// A rule referring to an unregistered action is rejected.
+	_, err = secretsmanagerWrapper.NewFromConfigFile(path,
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.ErrorContains(t, err, "reload-tls")
+
+	reloaded := make(chan string, 1)
+	secretsManager, err := secretsmanagerWrapper.NewFromConfigFile(path,
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithWatchAction("reload-tls", func(key, newVal string) {
+			reloaded <- key + "=" + newVal
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	secretsManager.StartWatchRules(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	updatedJSON, err := json.Marshal(map[string]string{"TLS_CERT": "rotatedCert"})
+	require.NoError(t, err)
+	smMock.secretValue.Store(string(updatedJSON))
+
+	select {
+	case got := <-reloaded:
+		require.Equal(t, "TLS_CERT=rotatedCert", got)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timeout waiting for watch action")
+	}
+}
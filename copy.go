@@ -0,0 +1,115 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/janduursma/aws-secretsmanager-wrapper-go/awsapi"
+)
+
+// WriterClient is implemented by Secrets Manager clients that can write secret values,
+// such as the default client.
+type WriterClient = awsapi.WriterClient
+
+// copyOptions holds the settings of CopyTo.
+type copyOptions struct {
+	include []string
+	exclude []string
+	client  WriterClient
+}
+
+// CopyOption defines a functional option for configuring CopyTo.
+type CopyOption func(*copyOptions)
+
+// CopyIncludeKeys restricts the copy to the given keys.
+func CopyIncludeKeys(keys ...string) CopyOption {
+	return func(o *copyOptions) {
+		o.include = append(o.include, keys...)
+	}
+}
+
+// CopyExcludeKeys leaves the given keys out of the copy.
+func CopyExcludeKeys(keys ...string) CopyOption {
+	return func(o *copyOptions) {
+		o.exclude = append(o.exclude, keys...)
+	}
+}
+
+// CopyWithClient writes the copy through client instead of the SecretsManager's own client,
+// e.g. a client for another account built from assumed-role credentials.
+func CopyWithClient(client WriterClient) CopyOption {
+	return func(o *copyOptions) {
+		o.client = client
+	}
+}
+
+// CopyTo reads the current payload of the secret from AWS and writes it as a new version of
+// targetSecretName, which must already exist. Without key filters the payload is copied verbatim;
+// with CopyIncludeKeys or CopyExcludeKeys only the selected top-level keys of its JSON object are
+// written, with their values, including nested objects, as they are.
+// References to other secrets are copied as they are, not resolved.
+func (s *SecretsManager) CopyTo(ctx context.Context, targetSecretName string, opts ...CopyOption) error {
+	var o copyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.client == nil {
+		writer, ok := s.secretsManagerAPI().(WriterClient)
+		if !ok {
+			return fmt.Errorf("copy to %s: Secrets Manager client cannot write secrets", targetSecretName)
+		}
+		o.client = writer
+	}
+
+	secret, err := s.fetchSecret(ctx, &secretsmanager.GetSecretValueInput{SecretId: &s.secretName})
+	if err != nil {
+		return err
+	}
+
+	input := &secretsmanager.PutSecretValueInput{SecretId: &targetSecretName}
+	switch {
+	case len(o.include) > 0 || len(o.exclude) > 0:
+		fields, err := secret.fields()
+		if err != nil {
+			return fmt.Errorf("copy to %s: key filters require a JSON object: %w", targetSecretName, err)
+		}
+		payload, err := json.Marshal(filterKeys(fields, o.include, o.exclude))
+		if err != nil {
+			return err
+		}
+		input.SecretString = aws.String(string(payload))
+	case secret.binary != nil:
+		input.SecretBinary = secret.binary
+	default:
+		input.SecretString = &secret.secretString
+	}
+
+	if _, err := o.client.PutSecretValue(ctx, input); err != nil {
+		return fmt.Errorf("copy to %s: %w", targetSecretName, err)
+	}
+	return nil
+}
+
+// filterKeys returns the entries of values selected by include, or all if include is empty,
+// minus those in exclude.
+func filterKeys(values map[string]json.RawMessage, include, exclude []string) map[string]json.RawMessage {
+	selected := make(map[string]json.RawMessage, len(values))
+	if len(include) > 0 {
+		for _, k := range include {
+			if v, ok := values[k]; ok {
+				selected[k] = v
+			}
+		}
+	} else {
+		for k, v := range values {
+			selected[k] = v
+		}
+	}
+	for _, k := range exclude {
+		delete(selected, k)
+	}
+	return selected
+}
@@ -0,0 +1,49 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_CopyTo(t *testing.T) {
+	smMock := &mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret":  `{"DB_USER":"admin","DB_PASSWORD":"password","STAGING_ONLY":"debug"}`,
+		"prod-secret":  `{}`,
+		"other-secret": `{}`,
+	}}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// Without filters, the payload is copied verbatim.
+	require.NoError(t, secretsManager.CopyTo(context.Background(), "prod-secret"))
+	require.Equal(t, smMock.secret("test-secret"), smMock.secret("prod-secret"))
+
+	// Keys can be excluded from the copy, and it can be written through another client.
+	target := &mockSecretsByIDClient{secrets: map[string]string{"prod-secret": `{}`}}
+	err := secretsManager.CopyTo(context.Background(), "prod-secret",
+		secretsmanagerWrapper.CopyExcludeKeys("STAGING_ONLY"),
+		secretsmanagerWrapper.CopyWithClient(target),
+	)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"DB_USER":"admin","DB_PASSWORD":"password"}`, target.secret("prod-secret"))
+
+	// Or restricted to the included ones.
+	err = secretsManager.CopyTo(context.Background(), "other-secret", secretsmanagerWrapper.CopyIncludeKeys("DB_USER"))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"DB_USER":"admin"}`, smMock.secret("other-secret"))
+
+	// Filters select top-level keys, and nested values are copied as they are.
+	nested := &mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret": `{"db":{"user":"admin","port":5432},"tags":["a"],"STAGING_ONLY":"debug"}`,
+		"prod-secret": `{}`,
+	}}
+	secretsManager = newSecretsManagerForTest(t, nested, &mockKMSClient{}, time.Minute)
+	require.NoError(t, secretsManager.CopyTo(context.Background(), "prod-secret", secretsmanagerWrapper.CopyExcludeKeys("STAGING_ONLY")))
+	require.JSONEq(t, `{"db":{"user":"admin","port":5432},"tags":["a"]}`, nested.secret("prod-secret"))
+
+	// The target secret must exist.
+	require.Error(t, secretsManager.CopyTo(context.Background(), "missing-secret"))
+}
@@ -0,0 +1,90 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/smithy-go"
+)
+
+// expiredCredentialsCodes are the AWS error codes returned when the request credentials expired.
+var expiredCredentialsCodes = []string{"ExpiredTokenException", "ExpiredToken"}
+
+// accessDeniedCodes are the AWS error codes returned when the request credentials lack permission.
+var accessDeniedCodes = []string{"AccessDeniedException", "AccessDenied"}
+
+// isExpiredCredentialsError reports whether err was caused by expired AWS credentials.
+func isExpiredCredentialsError(err error) bool {
+	if err == nil {
+		// Skip errors.As, whose target would escape to the heap on every successful call.
+		return false
+	}
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && slices.Contains(expiredCredentialsCodes, apiErr.ErrorCode())
+}
+
+// WithAccessDeniedRecovery refreshes the AWS credentials once the secret was denied after times
+// consecutive attempts, if it was fetched successfully before. The cached credentials are
+// invalidated, the default clients are rebuilt and the operation is tried once more. This picks up
+// IAM permission changes that take a while to propagate, and role policy updates, without a
+// restart. Clients injected through options are kept as they are.
+func WithAccessDeniedRecovery(times int) Option {
+	return func(s *SecretsManager) {
+		s.accessDeniedRecovery = max(times, 1)
+	}
+}
+
+// isAccessDeniedError reports whether err was caused by missing permissions.
+func isAccessDeniedError(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && slices.Contains(accessDeniedCodes, apiErr.ErrorCode())
+}
+
+// withFreshCredentials runs op. If op fails because the AWS credentials expired, or as set by
+// WithAccessDeniedRecovery, it invalidates the cached credentials, reloads the AWS config,
+// rebuilds the default clients and runs op once more. Long-lived processes can end up with a
+// stale credential chain in corner cases; this recovers without a restart.
+func (s *SecretsManager) withFreshCredentials(ctx context.Context, op func() error) error {
+	err := op()
+	if !isExpiredCredentialsError(err) && !s.shouldRecoverAccessDenied(ctx, err) {
+		return err
+	}
+	s.invalidateCredentials()
+	if rebuildErr := s.buildClients(ctx); rebuildErr != nil {
+		return errors.Join(err, rebuildErr)
+	}
+	return op()
+}
+
+// shouldRecoverAccessDenied counts the consecutive AccessDenied failures of a secret that was
+// fetched before, and reports whether the credentials should be refreshed after err.
+func (s *SecretsManager) shouldRecoverAccessDenied(ctx context.Context, err error) bool {
+	if s.accessDeniedRecovery == 0 {
+		return false
+	}
+	if !isAccessDeniedError(err) {
+		if err == nil {
+			s.accessDeniedCount.Store(0)
+		}
+		return false
+	}
+	if !s.fetched.Load() || s.accessDeniedCount.Add(1) < int32(s.accessDeniedRecovery) {
+		return false
+	}
+	s.accessDeniedCount.Store(0)
+	s.log().Info(ctx, "access denied repeatedly; refreshing AWS credentials", "secret", s.secretName, "error", err)
+	return true
+}
+
+// invalidateCredentials makes the credentials cache of the default clients, if any, retrieve
+// new credentials on their next use. Caches may be shared, e.g. through WithAWSConfig, so a
+// rebuild alone would keep the cached credentials.
+func (s *SecretsManager) invalidateCredentials() {
+	s.clients.mu.RLock()
+	defer s.clients.mu.RUnlock()
+	if cache, ok := s.clients.credentials.(*aws.CredentialsCache); ok {
+		cache.Invalidate()
+	}
+}
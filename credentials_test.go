@@ -0,0 +1,78 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// denyingHTTPClient answers GetSecretValue requests, or denies them while deny is set.
+type denyingHTTPClient struct {
+	deny atomic.Bool
+}
+
+func (c *denyingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	status, body := http.StatusOK, `{"Name":"test-secret","SecretString":"{\"DB_PASSWORD\":\"password\"}","VersionId":"v1"}`
+	if c.deny.Load() {
+		status, body = http.StatusBadRequest, `{"__type":"AccessDeniedException","message":"not authorized"}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.1"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestSecretsManager_WithAccessDeniedRecovery(t *testing.T) {
+	httpClient := &denyingHTTPClient{}
+	var retrievals atomic.Int32
+	cfg := aws.Config{
+		Region:     "us-test-1",
+		HTTPClient: httpClient,
+		Credentials: aws.NewCredentialsCache(aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			retrievals.Add(1)
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		})),
+	}
+	newSecretsManager := func() *secretsmanagerWrapper.SecretsManager {
+		secretsManager, err := secretsmanagerWrapper.NewSecretsManager("", "test-secret", "test-kms-key",
+			secretsmanagerWrapper.WithAWSConfig(cfg),
+			secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+			secretsmanagerWrapper.WithAccessDeniedRecovery(2),
+		)
+		require.NoError(t, err)
+		return secretsManager
+	}
+	ctx := context.Background()
+
+	// A secret that was never fetched does not refresh the credentials.
+	httpClient.deny.Store(true)
+	secretsManager := newSecretsManager()
+	for range 3 {
+		require.ErrorContains(t, secretsManager.Refresh(ctx), "AccessDeniedException")
+	}
+	require.Equal(t, int32(1), retrievals.Load())
+
+	httpClient.deny.Store(false)
+	secretsManager = newSecretsManager()
+	require.NoError(t, secretsManager.Refresh(ctx))
+
+	// The second consecutive denial refreshes the credentials and tries again.
+	httpClient.deny.Store(true)
+	require.Error(t, secretsManager.Refresh(ctx))
+	require.Equal(t, int32(1), retrievals.Load())
+	require.Error(t, secretsManager.Refresh(ctx))
+	require.Equal(t, int32(2), retrievals.Load())
+
+	// Once the permissions propagated, the secret is fetched again.
+	httpClient.deny.Store(false)
+	require.NoError(t, secretsManager.Refresh(ctx))
+}
@@ -0,0 +1,69 @@
+package secretsmanager
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"strings"
+	"text/template"
+)
+
+// DBConnector is a driver.Connector that builds the data source name of every new connection from
+// the current values of a secret, so that rotated database credentials are picked up without a
+// restart. Pass it to sql.OpenDB. Existing connections keep the credentials they were opened with.
+type DBConnector struct {
+	sm     *SecretsManager
+	driver driver.Driver
+	dsn    *template.Template
+}
+
+// NewDBConnector returns a DBConnector for the database/sql driver registered as driverName. The
+// data source name is rendered from dsnTemplate, a text/template with the keys of the secret as
+// fields, e.g. "{{.username}}:{{.password}}@tcp({{.host}}:{{.port}})/{{.dbname}}" for the format
+// of RDS secrets and the MySQL driver. Values are inserted as they are; the template functions
+// pathescape and queryescape escape them for URLs, e.g. "postgres://{{pathescape .username}}:...".
+// A key missing from the secret makes the connection fail.
+func NewDBConnector(sm *SecretsManager, driverName, dsnTemplate string) (*DBConnector, error) {
+	tmpl, err := template.New("dsn").Option("missingkey=error").Funcs(template.FuncMap{
+		"pathescape":  url.PathEscape,
+		"queryescape": url.QueryEscape,
+	}).Parse(dsnTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DSN template: %w", err)
+	}
+	// sql.Open does not connect; it only looks up the driver.
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return &DBConnector{sm: sm, driver: db.Driver(), dsn: tmpl}, nil
+}
+
+// Connect implements driver.Connector. It reads the secret, which is refreshed first if the cache
+// is expired, and opens a connection with the rendered data source name.
+func (c *DBConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	values, err := c.sm.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var dsn strings.Builder
+	if err := c.dsn.Execute(&dsn, values); err != nil {
+		return nil, fmt.Errorf("failed to render DSN from secret %q: %w", c.sm.secretName, err)
+	}
+	if d, ok := c.driver.(driver.DriverContext); ok {
+		connector, err := d.OpenConnector(dsn.String())
+		if err != nil {
+			return nil, err
+		}
+		return connector.Connect(ctx)
+	}
+	return c.driver.Open(dsn.String())
+}
+
+// Driver implements driver.Connector.
+func (c *DBConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,14 @@
+package secretsmanager
+
+import "maps"
+
+// WithDefaults provides fallback values for keys that are missing from the secret, e.g. while a
+// new key is rolled out to the secrets of all environments. Get returns the default and logs that
+// the key was defaulted. Keys that are present in the secret always win, even if empty.
+// While the cache is fresh, defaults are served without fetching the secret again; a key added to
+// the secret is picked up with the next refresh.
+func WithDefaults(defaults map[string]string) Option {
+	return func(s *SecretsManager) {
+		s.defaults = maps.Clone(defaults)
+	}
+}
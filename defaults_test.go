@@ -0,0 +1,73 @@
+package secretsmanager_test
+
+import (
+	"bytes"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_WithDefaults(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password","FEATURE_FLAG":""}`)
+	var logs bytes.Buffer
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+		secretsmanagerWrapper.WithSlogLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		secretsmanagerWrapper.WithDefaults(map[string]string{
+			"DB_PASSWORD":  "default",
+			"FEATURE_FLAG": "on",
+			"NEW_KEY":      "fallback",
+		}),
+	)
+	require.NoError(t, err)
+
+	for key, want := range map[string]string{"DB_PASSWORD": "password", "FEATURE_FLAG": "", "NEW_KEY": "fallback"} {
+		val, err := secretsManager.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, want, val, key)
+	}
+	require.Contains(t, logs.String(), "key=NEW_KEY")
+
+	_, err = secretsManager.Get("MISSING")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+}
+
+func TestSecretsManager_WithDefaults_FreshCacheDoesNotFetch(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+		secretsmanagerWrapper.WithDefaults(map[string]string{"NEW_KEY": "fallback"}),
+	)
+	require.NoError(t, err)
+
+	// The default is served from the fresh cache, without fetching the secret for every read.
+	for range 5 {
+		val, err := secretsManager.Get("NEW_KEY")
+		require.NoError(t, err)
+		require.Equal(t, "fallback", val)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+
+	// Keys missing without a default are not fetched again either.
+	_, err = secretsManager.Get("MISSING")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+
+	// Once the key is added and the cache invalidated, its value is fetched.
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password","NEW_KEY":"value"}`)
+	secretsManager.Invalidate("NEW_KEY")
+	val, err := secretsManager.Get("NEW_KEY")
+	require.NoError(t, err)
+	require.Equal(t, "value", val)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+}
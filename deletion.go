@@ -0,0 +1,77 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/janduursma/aws-secretsmanager-wrapper-go/awsapi"
+)
+
+// ErrSecretScheduledForDeletion is returned when the secret cannot be read because it is scheduled
+// for deletion. The returned error is a *SecretDeletionError.
+var ErrSecretScheduledForDeletion = errors.New("secret is scheduled for deletion")
+
+// SecretDescriber is implemented by Secrets Manager clients that can describe secrets, such as the
+// default client. It is used to tell a secret that is scheduled for deletion from other failures.
+type SecretDescriber = awsapi.SecretDescriber
+
+// SecretDeletionError reports that a secret cannot be read because it is scheduled for deletion.
+// It can be restored with RestoreSecret until DeletedDate.
+type SecretDeletionError struct {
+	SecretID string
+	// DeletedDate is the date on which the secret will be deleted.
+	DeletedDate time.Time
+	// Err is the error returned by GetSecretValue.
+	Err error
+}
+
+// Error implements error.
+func (e *SecretDeletionError) Error() string {
+	return fmt.Sprintf("secret %s is scheduled for deletion on %s: %v", e.SecretID, e.DeletedDate.Format(time.RFC3339), e.Err)
+}
+
+// Is reports whether target is ErrSecretScheduledForDeletion.
+func (e *SecretDeletionError) Is(target error) bool {
+	return target == ErrSecretScheduledForDeletion
+}
+
+// Unwrap returns the error returned by GetSecretValue.
+func (e *SecretDeletionError) Unwrap() error {
+	return e.Err
+}
+
+// checkScheduledForDeletion returns a *SecretDeletionError if err, returned by GetSecretValue,
+// was caused by secretID being scheduled for deletion, and err otherwise.
+// Secrets Manager reports this as an InvalidRequestException, which is only told apart from
+// other invalid requests by describing the secret.
+func (s *SecretsManager) checkScheduledForDeletion(ctx context.Context, secretID string, err error) error {
+	var invalidRequest *types.InvalidRequestException
+	if !errors.As(err, &invalidRequest) {
+		return err
+	}
+	describer, ok := s.secretsManagerAPI().(SecretDescriber)
+	if !ok {
+		return err
+	}
+	out, describeErr := describer.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: &secretID})
+	if describeErr != nil || out.DeletedDate == nil {
+		return err
+	}
+	return &SecretDeletionError{SecretID: secretID, DeletedDate: *out.DeletedDate, Err: err}
+}
+
+// logScheduledForDeletion logs an error if err reports that the secret is scheduled for deletion,
+// and reports whether it did.
+func (s *SecretsManager) logScheduledForDeletion(ctx context.Context, err error) bool {
+	var deletionErr *SecretDeletionError
+	if !errors.As(err, &deletionErr) {
+		return false
+	}
+	s.log().Error(ctx, "watched secret is scheduled for deletion; restore it to resume reads",
+		"secret", deletionErr.SecretID, "deletedDate", deletionErr.DeletedDate)
+	return true
+}
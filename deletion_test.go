@@ -0,0 +1,64 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mockDeletedSecretClient simulates a Secrets Manager client for a secret that may be scheduled for deletion.
+type mockDeletedSecretClient struct {
+	// deletedDate is reported by DescribeSecret, unless nil.
+	deletedDate *time.Time
+	callCount   int32
+}
+
+// GetSecretValue simulates the AWS SDK GetSecretValue method, failing as AWS does for deleted secrets.
+func (m *mockDeletedSecretClient) GetSecretValue(_ context.Context, _ *awsSecretsManager.GetSecretValueInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
+	atomic.AddInt32(&m.callCount, 1)
+	return nil, &types.InvalidRequestException{Message: aws.String("You can't perform this operation on the secret because it was marked for deletion.")}
+}
+
+// DescribeSecret simulates the AWS SDK DescribeSecret method.
+func (m *mockDeletedSecretClient) DescribeSecret(_ context.Context, input *awsSecretsManager.DescribeSecretInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.DescribeSecretOutput, error) {
+	return &awsSecretsManager.DescribeSecretOutput{Name: input.SecretId, DeletedDate: m.deletedDate}, nil
+}
+
+func TestSecretsManager_Get_ScheduledForDeletion(t *testing.T) {
+	deletedDate := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	smMock := &mockDeletedSecretClient{deletedDate: &deletedDate}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	_, err := secretsManager.Get("DB_PASSWORD")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretScheduledForDeletion)
+	var deletionErr *secretsmanagerWrapper.SecretDeletionError
+	require.True(t, errors.As(err, &deletionErr))
+	require.Equal(t, "test-secret", deletionErr.SecretID)
+	require.Equal(t, deletedDate, deletionErr.DeletedDate)
+
+	// The read is not retried, as retrying cannot succeed.
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_Get_InvalidRequestNotDeleted(t *testing.T) {
+	smMock := &mockDeletedSecretClient{}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithRetry(1, time.Millisecond, time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	_, err = secretsManager.Get("DB_PASSWORD")
+	var invalidRequest *types.InvalidRequestException
+	require.ErrorAs(t, err, &invalidRequest)
+	require.NotErrorIs(t, err, secretsmanagerWrapper.ErrSecretScheduledForDeletion)
+}
@@ -0,0 +1,48 @@
+package secretsmanager
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Environment variables read by NewFromEnv.
+const (
+	EnvRegion     = "SECRETS_WRAPPER_REGION"
+	EnvSecretName = "SECRETS_WRAPPER_SECRET_NAME"
+	EnvKMSKeyID   = "SECRETS_WRAPPER_KMS_KEY_ID"
+	EnvCacheTTL   = "SECRETS_WRAPPER_CACHE_TTL"
+	EnvSoftTTL    = "SECRETS_WRAPPER_SOFT_TTL"
+)
+
+// NewFromEnv creates a new SecretsManager configured from SECRETS_WRAPPER_* environment variables.
+// SECRETS_WRAPPER_SECRET_NAME is required, and so is SECRETS_WRAPPER_KMS_KEY_ID unless the cache is
+// encrypted otherwise, e.g. with WithCacheCipher; the configuration is validated as by
+// NewSecretsManager. Durations are parsed
+// with time.ParseDuration. If SECRETS_WRAPPER_REGION is unset, the region is resolved by the AWS SDK.
+// Options passed explicitly override the environment.
+func NewFromEnv(opts ...Option) (*SecretsManager, error) {
+	secretName := os.Getenv(EnvSecretName)
+	if secretName == "" {
+		return nil, fmt.Errorf("%s is not set", EnvSecretName)
+	}
+	kmsKeyID := os.Getenv(EnvKMSKeyID)
+
+	var envOpts []Option
+	if v := os.Getenv(EnvCacheTTL); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvCacheTTL, err)
+		}
+		envOpts = append(envOpts, WithCacheTTL(ttl))
+	}
+	if v := os.Getenv(EnvSoftTTL); v != "" {
+		ttl, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvSoftTTL, err)
+		}
+		envOpts = append(envOpts, WithSoftTTL(ttl))
+	}
+
+	return NewSecretsManager(os.Getenv(EnvRegion), secretName, kmsKeyID, append(envOpts, opts...)...)
+}
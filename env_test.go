@@ -0,0 +1,75 @@
+package secretsmanager_test
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromEnv(t *testing.T) {
+	t.Setenv(secretsmanagerWrapper.EnvRegion, "us-test-1")
+	t.Setenv(secretsmanagerWrapper.EnvSecretName, "test-secret")
+	t.Setenv(secretsmanagerWrapper.EnvKMSKeyID, "test-kms-key")
+	t.Setenv(secretsmanagerWrapper.EnvCacheTTL, "50ms")
+
+	secretJSON, err := json.Marshal(map[string]string{"DB_PASSWORD": "password"})
+	require.NoError(t, err)
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+
+	secretsManager, err := secretsmanagerWrapper.NewFromEnv(
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	// The cache TTL from the environment applies.
+	time.Sleep(60 * time.Millisecond)
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestNewFromEnv_KMSKey(t *testing.T) {
+	t.Setenv(secretsmanagerWrapper.EnvRegion, "us-test-1")
+	t.Setenv(secretsmanagerWrapper.EnvSecretName, "test-secret")
+	t.Setenv(secretsmanagerWrapper.EnvKMSKeyID, "")
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+
+	// Without a KMS key, the cache must be encrypted otherwise.
+	_, err := secretsmanagerWrapper.NewFromEnv(
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrInvalidConfig)
+
+	secretsManager, err := secretsmanagerWrapper.NewFromEnv(
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithoutCacheEncryption(),
+	)
+	require.NoError(t, err)
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+}
+
+func TestNewFromEnv_Invalid(t *testing.T) {
+	t.Setenv(secretsmanagerWrapper.EnvSecretName, "")
+	_, err := secretsmanagerWrapper.NewFromEnv()
+	require.ErrorContains(t, err, secretsmanagerWrapper.EnvSecretName)
+
+	t.Setenv(secretsmanagerWrapper.EnvSecretName, "test-secret")
+	t.Setenv(secretsmanagerWrapper.EnvKMSKeyID, "test-kms-key")
+	t.Setenv(secretsmanagerWrapper.EnvCacheTTL, "ten minutes")
+	_, err = secretsmanagerWrapper.NewFromEnv()
+	require.ErrorContains(t, err, secretsmanagerWrapper.EnvCacheTTL)
+}
@@ -0,0 +1,82 @@
+package secretsmanager_test
+
+import (
+	"bytes"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mockKMSDataKeyClient simulates a KMS client that generates data keys. Like mockKMSClient, it
+// does no real encryption, so encrypted data keys are the data keys themselves.
+type mockKMSDataKeyClient struct {
+	mockKMSClient
+	encryptCount   int32
+	dataKeyCount   int32
+	decryptCount   int32
+	requestedKeyID atomic.Value
+}
+
+func (m *mockKMSDataKeyClient) Encrypt(ctx context.Context, input *kms.EncryptInput, opts ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	atomic.AddInt32(&m.encryptCount, 1)
+	return m.mockKMSClient.Encrypt(ctx, input, opts...)
+}
+
+func (m *mockKMSDataKeyClient) Decrypt(ctx context.Context, input *kms.DecryptInput, opts ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	atomic.AddInt32(&m.decryptCount, 1)
+	return &kms.DecryptOutput{Plaintext: bytes.Clone(input.CiphertextBlob)}, nil
+}
+
+func (m *mockKMSDataKeyClient) GenerateDataKey(_ context.Context, input *kms.GenerateDataKeyInput, _ ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+	n := atomic.AddInt32(&m.dataKeyCount, 1)
+	m.requestedKeyID.Store(*input.KeyId)
+	key := bytes.Repeat([]byte{byte(n)}, 32)
+	return &kms.GenerateDataKeyOutput{Plaintext: key, CiphertextBlob: bytes.Clone(key)}, nil
+}
+
+func TestSecretsManager_WithEnvelopeEncryption(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_USER":"admin","DB_PASSWORD":"password","API_KEY":"key"}`)
+	kmsMock := &mockKMSDataKeyClient{}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(kmsMock),
+		secretsmanagerWrapper.WithEnvelopeEncryption(),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+	)
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	// A refresh generates a single data key and never calls Encrypt.
+	require.Equal(t, int32(1), atomic.LoadInt32(&kmsMock.dataKeyCount))
+	require.Equal(t, int32(0), atomic.LoadInt32(&kmsMock.encryptCount))
+	require.Equal(t, "test-kms-key", kmsMock.requestedKeyID.Load())
+
+	// Cache hits decrypt the data key.
+	val, err = secretsManager.Get("DB_USER")
+	require.NoError(t, err)
+	require.Equal(t, "admin", val)
+	require.Equal(t, int32(2), atomic.LoadInt32(&kmsMock.decryptCount))
+}
+
+func TestSecretsManager_WithEnvelopeEncryption_NoDataKeys(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithEnvelopeEncryption(),
+	)
+	require.NoError(t, err)
+
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.Error(t, err)
+}
@@ -0,0 +1,47 @@
+package secretsmanager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// envPlaceholder is replaced by the environment in secret names, e.g. "myapp/{env}/secrets".
+const envPlaceholder = "{env}"
+
+// ErrEnvironmentMismatch is returned by NewSecretsManager when the configured environment
+// does not match the one expected by WithEnvironmentGuard.
+var ErrEnvironmentMismatch = errors.New("environment mismatch")
+
+// WithEnvironment sets the environment the secret name is resolved for.
+// Every "{env}" in the secret name is replaced by env, so that the same binary
+// reads "myapp/staging/secrets" in staging and "myapp/prod/secrets" in production.
+func WithEnvironment(env string) Option {
+	return func(s *SecretsManager) {
+		s.environment = env
+	}
+}
+
+// WithEnvironmentGuard makes NewSecretsManager fail with ErrEnvironmentMismatch unless the
+// environment set with WithEnvironment equals expected, typically the deployment tag of the
+// running service. This prevents a misconfigured service from reading another environment's secrets.
+func WithEnvironmentGuard(expected string) Option {
+	return func(s *SecretsManager) {
+		s.expectedEnvironment = expected
+	}
+}
+
+// resolveEnvironment applies the environment to the secret name and enforces the environment guard.
+func (s *SecretsManager) resolveEnvironment() error {
+	if s.expectedEnvironment != "" && s.environment != s.expectedEnvironment {
+		return fmt.Errorf("%w: configured %q, expected %q", ErrEnvironmentMismatch, s.environment, s.expectedEnvironment)
+	}
+	if !strings.Contains(s.secretName, envPlaceholder) {
+		return nil
+	}
+	if s.environment == "" {
+		return fmt.Errorf("secret name %q requires an environment", s.secretName)
+	}
+	s.secretName = strings.ReplaceAll(s.secretName, envPlaceholder, s.environment)
+	return nil
+}
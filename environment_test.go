@@ -0,0 +1,41 @@
+package secretsmanager_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEnvironment(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"DB_PASSWORD": "password"})
+	require.NoError(t, err)
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "myapp/{env}/secrets", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithEnvironment("staging"),
+		secretsmanagerWrapper.WithEnvironmentGuard("staging"),
+	)
+	require.NoError(t, err)
+
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "myapp/staging/secrets", smMock.requestedSecretID.Load())
+}
+
+func TestWithEnvironment_Invalid(t *testing.T) {
+	// The environment must match the guard.
+	_, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "myapp/{env}/secrets", "test-kms-key",
+		secretsmanagerWrapper.WithEnvironment("staging"),
+		secretsmanagerWrapper.WithEnvironmentGuard("prod"),
+	)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrEnvironmentMismatch)
+
+	// A secret name with a placeholder requires an environment.
+	_, err = secretsmanagerWrapper.NewSecretsManager("us-test-1", "myapp/{env}/secrets", "test-kms-key")
+	require.ErrorContains(t, err, "requires an environment")
+}
@@ -0,0 +1,149 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+// SQSClient defines the subset of methods needed from the AWS SQS client to receive events.
+type SQSClient interface {
+	ReceiveMessage(ctx context.Context, input *sqs.ReceiveMessageInput, opts ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, input *sqs.DeleteMessageInput, opts ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
+
+// The default SQS client implements SQSClient.
+var _ SQSClient = (*sqs.Client)(nil)
+
+// secretEventNames are the CloudTrail events that change the value of a secret.
+var secretEventNames = []string{
+	"RotationSucceeded",
+	"PutSecretValue",
+	"UpdateSecret",
+	"UpdateSecretVersionStage",
+	"RestoreSecret",
+}
+
+// secretEvent is the part of an EventBridge event for a Secrets Manager CloudTrail event that is
+// needed to tell which secret it changed.
+type secretEvent struct {
+	Source string `json:"source"`
+	Detail struct {
+		EventName         string `json:"eventName"`
+		RequestParameters struct {
+			SecretID string `json:"secretId"`
+		} `json:"requestParameters"`
+		AdditionalEventData struct {
+			SecretID string `json:"SecretId"`
+		} `json:"additionalEventData"`
+	} `json:"detail"`
+}
+
+// HandleSecretEvent handles an EventBridge event for Secrets Manager, as delivered by an
+// EventBridge rule on source "aws.secretsmanager". If the event changed the value of this secret,
+// such as a completed rotation, the cache is refreshed and watchers poll right away. It reports
+// whether the event concerned this secret.
+func (s *SecretsManager) HandleSecretEvent(ctx context.Context, event []byte) (bool, error) {
+	var e secretEvent
+	if err := json.Unmarshal(event, &e); err != nil {
+		return false, err
+	}
+	if e.Source != "aws.secretsmanager" || !slices.Contains(secretEventNames, e.Detail.EventName) {
+		return false, nil
+	}
+	secretID := e.Detail.RequestParameters.SecretID
+	if secretID == "" {
+		secretID = e.Detail.AdditionalEventData.SecretID
+	}
+	if !sameSecret(secretID, s.secretName) {
+		return false, nil
+	}
+
+	s.log().Info(ctx, "secret changed; refreshing", "secret", s.secretName, "event", e.Detail.EventName)
+	err := s.refresh(ctx)
+	s.pokeWatchers()
+	return true, err
+}
+
+// sameSecret reports whether the secret IDs a and b, names or ARNs, refer to the same secret.
+// ARNs must agree on partition, region and account. Full ARNs end in a hyphen and six random
+// characters, which are not part of the name, so the name in an ARN also matches without them.
+func sameSecret(a, b string) bool {
+	aName, aARN := secretNameOf(a)
+	bName, bARN := secretNameOf(b)
+	if aARN != nil && bARN != nil &&
+		(aARN.Partition != bARN.Partition || aARN.Region != bARN.Region || aARN.AccountID != bARN.AccountID) {
+		return false
+	}
+	if aName == bName {
+		return true
+	}
+	return aARN != nil && trimARNSuffix(aName) == bName || bARN != nil && aName == trimARNSuffix(bName)
+}
+
+// secretNameOf returns the name of the secret that secretID refers to, as it appears in the ID,
+// and the parsed ARN if secretID is one.
+func secretNameOf(secretID string) (string, *arn.ARN) {
+	parsed, err := arn.Parse(secretID)
+	if err != nil {
+		return secretID, nil
+	}
+	return strings.TrimPrefix(parsed.Resource, "secret:"), &parsed
+}
+
+// trimARNSuffix removes the hyphen and six random alphanumeric characters that full ARNs append
+// to the name of a secret, if name ends in them.
+func trimARNSuffix(name string) string {
+	i := len(name) - 7
+	if i <= 0 || name[i] != '-' {
+		return name
+	}
+	for _, c := range name[i+1:] {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return name
+		}
+	}
+	return name[:i]
+}
+
+// StartEventListener starts a background goroutine that receives Secrets Manager events from the
+// SQS queue at queueURL, fed by an EventBridge rule, and handles them with HandleSecretEvent,
+// until ctx is done. Rotations are then picked up as soon as they complete, without polling.
+// Every message is deleted once handled, so each process needs a queue of its own. Messages
+// that fail to be handled are left on the queue to be retried.
+func (s *SecretsManager) StartEventListener(ctx context.Context, client SQSClient, queueURL string) {
+	go func() {
+		for ctx.Err() == nil {
+			out, err := client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+				QueueUrl:            &queueURL,
+				MaxNumberOfMessages: 10,
+				WaitTimeSeconds:     20,
+			})
+			if err != nil {
+				if ctx.Err() == nil {
+					s.log().Error(ctx, "failed to receive secret events", "queue", queueURL, "error", err)
+					_ = sleepContext(ctx, time.Second)
+				}
+				continue
+			}
+			for _, msg := range out.Messages {
+				if _, err := s.HandleSecretEvent(ctx, []byte(aws.ToString(msg.Body))); err != nil {
+					s.log().Error(ctx, "failed to handle secret event", "secret", s.secretName, "error", err)
+					continue
+				}
+				if _, err := client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+					QueueUrl:      &queueURL,
+					ReceiptHandle: msg.ReceiptHandle,
+				}); err != nil && ctx.Err() == nil {
+					s.log().Error(ctx, "failed to delete secret event", "queue", queueURL, "error", err)
+				}
+			}
+		}
+	}()
+}
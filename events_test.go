@@ -0,0 +1,155 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// rotationEvent is an EventBridge event for a completed rotation of the test secret.
+const rotationEvent = `{
+	"source": "aws.secretsmanager",
+	"detail-type": "AWS Service Event via CloudTrail",
+	"detail": {
+		"eventName": "RotationSucceeded",
+		"additionalEventData": {"SecretId": "arn:aws:secretsmanager:us-test-1:123456789012:secret:test-secret-AbCdEf"}
+	}
+}`
+
+// mockSQSClient simulates an SQS queue holding the given message bodies.
+type mockSQSClient struct {
+	mu       sync.Mutex
+	messages []string
+	deleted  []string
+}
+
+func (m *mockSQSClient) ReceiveMessage(ctx context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	m.mu.Lock()
+	messages := m.messages
+	m.messages = nil
+	m.mu.Unlock()
+	if len(messages) == 0 {
+		// Simulate long polling.
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+	out := &sqs.ReceiveMessageOutput{}
+	for _, body := range messages {
+		out.Messages = append(out.Messages, sqsTypes.Message{Body: aws.String(body), ReceiptHandle: aws.String(body)})
+	}
+	return out, nil
+}
+
+func (m *mockSQSClient) DeleteMessage(_ context.Context, input *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted = append(m.deleted, aws.ToString(input.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func TestSecretsManager_HandleSecretEvent(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"initialPassword"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+	_, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+
+	// Events for other secrets or other operations are ignored.
+	for _, event := range []string{
+		`{"source": "aws.secretsmanager", "detail": {"eventName": "PutSecretValue", "requestParameters": {"secretId": "other-secret"}}}`,
+		`{"source": "aws.secretsmanager", "detail": {"eventName": "GetSecretValue", "requestParameters": {"secretId": "test-secret"}}}`,
+		// A secret whose name only shares a prefix with the test secret.
+		`{"source": "aws.secretsmanager", "detail": {"eventName": "PutSecretValue", "requestParameters": {"secretId": "test-secret-backup"}}}`,
+		`{"source": "aws.secretsmanager", "detail": {"eventName": "PutSecretValue", "requestParameters": {"secretId": "test-secret-v2"}}}`,
+	} {
+		handled, err := secretsManager.HandleSecretEvent(context.Background(), []byte(event))
+		require.NoError(t, err)
+		require.False(t, handled)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+
+	smMock.secretValue.Store(`{"DB_PASSWORD":"rotatedPassword"}`)
+	handled, err := secretsManager.HandleSecretEvent(context.Background(), []byte(rotationEvent))
+	require.NoError(t, err)
+	require.True(t, handled)
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "rotatedPassword", val)
+}
+
+func TestSecretsManager_StartEventListener(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"initialPassword"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The watcher polls far less often than the event arrives.
+	callbackCh := make(chan string, 1)
+	watcher := secretsManager.Watch(ctx, "DB_PASSWORD", time.Hour, func(newVal string) {
+		callbackCh <- newVal
+	})
+	require.Eventually(t, func() bool {
+		return watcher.LastValue() == "initialPassword"
+	}, time.Second, 5*time.Millisecond)
+
+	sqsMock := &mockSQSClient{}
+	secretsManager.StartEventListener(ctx, sqsMock, "https://sqs.us-test-1.amazonaws.com/123456789012/secret-events")
+	smMock.secretValue.Store(`{"DB_PASSWORD":"rotatedPassword"}`)
+	sqsMock.mu.Lock()
+	sqsMock.messages = []string{rotationEvent}
+	sqsMock.mu.Unlock()
+
+	select {
+	case newVal := <-callbackCh:
+		require.Equal(t, "rotatedPassword", newVal)
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for watcher callback")
+	}
+	require.Eventually(t, func() bool {
+		sqsMock.mu.Lock()
+		defer sqsMock.mu.Unlock()
+		return len(sqsMock.deleted) == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSecretsManager_HandleSecretEvent_ARN(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("", "arn:aws:secretsmanager:us-test-1:123456789012:secret:test-secret",
+		"test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+	)
+	require.NoError(t, err)
+
+	event := func(secretID string) []byte {
+		return []byte(`{"source": "aws.secretsmanager", "detail": {"eventName": "PutSecretValue", "requestParameters": {"secretId": "` + secretID + `"}}}`)
+	}
+	for secretID, want := range map[string]bool{
+		"arn:aws:secretsmanager:us-test-1:123456789012:secret:test-secret-AbCdEf": true,
+		"arn:aws:secretsmanager:us-test-1:123456789012:secret:test-secret":        true,
+		"test-secret": true,
+		// The same name in another account or region is another secret.
+		"arn:aws:secretsmanager:us-test-1:210987654321:secret:test-secret-AbCdEf": false,
+		"arn:aws:secretsmanager:us-test-2:123456789012:secret:test-secret-AbCdEf": false,
+		// A suffix that is not six alphanumeric characters is part of the name.
+		"arn:aws:secretsmanager:us-test-1:123456789012:secret:test-secret-a_b_cd": false,
+	} {
+		handled, err := secretsManager.HandleSecretEvent(context.Background(), event(secretID))
+		require.NoError(t, err)
+		require.Equal(t, want, handled, secretID)
+	}
+}
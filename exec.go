@@ -0,0 +1,43 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"sort"
+)
+
+// ExecWithSecrets runs the command argv with the secret values of mapping, which maps environment
+// variable names to secret keys, added to its environment. The values are only set for the child
+// process, never in the environment of the current process. The child inherits the standard
+// streams, and is killed if ctx is done before it exits. A non-zero exit status is returned as
+// an *exec.ExitError.
+func (s *SecretsManager) ExecWithSecrets(ctx context.Context, argv []string, mapping map[string]string) error {
+	if len(argv) == 0 {
+		return errors.New("no command given")
+	}
+
+	// Resolve every key before starting the command, in a deterministic order.
+	names := make([]string, 0, len(mapping))
+	for name := range mapping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	env := os.Environ()
+	for _, name := range names {
+		val, err := s.GetWithContext(ctx, mapping[name])
+		if err != nil {
+			return err
+		}
+		// Later entries take precedence, so these override inherited variables.
+		env = append(env, name+"="+val)
+	}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,40 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_ExecWithSecrets(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+	t.Setenv("PGPASSWORD", "inherited")
+
+	// The mapped value overrides the inherited variable in the child only.
+	err := secretsManager.ExecWithSecrets(context.Background(),
+		[]string{"sh", "-c", `test "$PGPASSWORD" = password`},
+		map[string]string{"PGPASSWORD": "DB_PASSWORD"})
+	require.NoError(t, err)
+	require.Equal(t, "inherited", os.Getenv("PGPASSWORD"))
+
+	// A failing command is reported with its exit status.
+	err = secretsManager.ExecWithSecrets(context.Background(), []string{"sh", "-c", "exit 3"}, nil)
+	var exitErr *exec.ExitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, 3, exitErr.ExitCode())
+
+	// Missing keys fail before the command is started.
+	err = secretsManager.ExecWithSecrets(context.Background(), []string{"sh", "-c", "exit 0"},
+		map[string]string{"API_KEY": "MISSING"})
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+}
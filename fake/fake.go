@@ -0,0 +1,217 @@
+// Package fake provides an in-memory Secrets Manager client for testing code that uses the
+// secretsmanager package, including its handling of secret rotation.
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+)
+
+// Staging labels of secret versions, as used by AWS Secrets Manager.
+const (
+	stageCurrent  = "AWSCURRENT"
+	stagePending  = "AWSPENDING"
+	stagePrevious = "AWSPREVIOUS"
+)
+
+// Client simulates the Secrets Manager client for a single secret, whose value is a JSON object.
+// The SecretId of requests is not checked. It implements GetSecretValue, PutSecretValue and
+// DescribeSecret, and is safe for concurrent use.
+type Client struct {
+	mu sync.Mutex
+	// versions maps VersionIds to secret values.
+	versions map[string]string
+	// stages maps staging labels to VersionIds.
+	stages      map[string]string
+	next        int
+	lastChanged time.Time
+	lastRotated time.Time
+}
+
+// NewClient returns a Client whose current version holds values.
+func NewClient(values map[string]string) *Client {
+	c := &Client{versions: map[string]string{}, stages: map[string]string{}}
+	c.promote(c.addVersion(marshal(values)))
+	return c
+}
+
+// addVersion stores a new version with value and returns its VersionId.
+func (c *Client) addVersion(value string) string {
+	c.next++
+	versionID := fmt.Sprintf("version-%d", c.next)
+	c.versions[versionID] = value
+	return versionID
+}
+
+// marshal returns values as a JSON object.
+func marshal(values map[string]string) string {
+	data, err := json.Marshal(values)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// promote moves AWSCURRENT to versionID and AWSPREVIOUS to the version that was current.
+func (c *Client) promote(versionID string) {
+	if current, ok := c.stages[stageCurrent]; ok {
+		c.stages[stagePrevious] = current
+	}
+	c.stages[stageCurrent] = versionID
+	if c.stages[stagePending] == versionID {
+		delete(c.stages, stagePending)
+	}
+	c.lastChanged = time.Now()
+}
+
+// GetSecretValue implements the AWS SDK method. Like AWS, it returns the AWSCURRENT version unless
+// a VersionId or VersionStage is given.
+func (c *Client) GetSecretValue(_ context.Context, input *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	versionID := c.stages[stageCurrent]
+	if input.VersionStage != nil {
+		versionID = c.stages[*input.VersionStage]
+	}
+	if input.VersionId != nil {
+		versionID = *input.VersionId
+	}
+	value, ok := c.versions[versionID]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("Secrets Manager can't find the specified secret value")}
+	}
+	return &secretsmanager.GetSecretValueOutput{
+		Name:          input.SecretId,
+		SecretString:  aws.String(value),
+		VersionId:     aws.String(versionID),
+		VersionStages: c.stagesOf(versionID),
+	}, nil
+}
+
+// PutSecretValue implements the AWS SDK method. The new version becomes AWSCURRENT, unless other
+// VersionStages are given.
+func (c *Client) PutSecretValue(_ context.Context, input *secretsmanager.PutSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.PutSecretValueOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	versionID := c.addVersion(aws.ToString(input.SecretString))
+	stages := input.VersionStages
+	if len(stages) == 0 {
+		stages = []string{stageCurrent}
+	}
+	for _, stage := range stages {
+		if stage == stageCurrent {
+			c.promote(versionID)
+		} else {
+			c.stages[stage] = versionID
+		}
+	}
+	return &secretsmanager.PutSecretValueOutput{
+		Name:          input.SecretId,
+		VersionId:     aws.String(versionID),
+		VersionStages: c.stagesOf(versionID),
+	}, nil
+}
+
+// DescribeSecret implements the AWS SDK method.
+func (c *Client) DescribeSecret(_ context.Context, input *secretsmanager.DescribeSecretInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.DescribeSecretOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	versionIDsToStages := map[string][]string{}
+	for stage, versionID := range c.stages {
+		versionIDsToStages[versionID] = append(versionIDsToStages[versionID], stage)
+	}
+	out := &secretsmanager.DescribeSecretOutput{
+		Name:               input.SecretId,
+		LastChangedDate:    aws.Time(c.lastChanged),
+		VersionIdsToStages: versionIDsToStages,
+	}
+	if !c.lastRotated.IsZero() {
+		out.RotationEnabled = aws.Bool(true)
+		out.LastRotatedDate = aws.Time(c.lastRotated)
+	}
+	return out, nil
+}
+
+// stagesOf returns the sorted staging labels of versionID.
+func (c *Client) stagesOf(versionID string) []string {
+	var stages []string
+	for stage, id := range c.stages {
+		if id == versionID {
+			stages = append(stages, stage)
+		}
+	}
+	slices.Sort(stages)
+	return stages
+}
+
+// rotateOptions holds the settings of Rotate.
+type rotateOptions struct {
+	startAfter time.Duration
+	pendingFor time.Duration
+	onStage    func(stage string)
+}
+
+// RotateOption defines a functional option for configuring Rotate.
+type RotateOption func(*rotateOptions)
+
+// RotateStartAfter delays the creation of the AWSPENDING version by d.
+func RotateStartAfter(d time.Duration) RotateOption {
+	return func(o *rotateOptions) {
+		o.startAfter = d
+	}
+}
+
+// RotatePendingFor keeps the new version staged as AWSPENDING for d before promoting it to
+// AWSCURRENT, like a rotation function that sets and tests the new credentials first.
+func RotatePendingFor(d time.Duration) RotateOption {
+	return func(o *rotateOptions) {
+		o.pendingFor = d
+	}
+}
+
+// RotateOnStage calls f with AWSPENDING and then AWSCURRENT as the new version reaches each stage,
+// so tests can act at each step of the rotation.
+func RotateOnStage(f func(stage string)) RotateOption {
+	return func(o *rotateOptions) {
+		o.onStage = f
+	}
+}
+
+// Rotate simulates a rotation of the secret to newValues, as done by a rotation function: the new
+// version is staged as AWSPENDING, then promoted to AWSCURRENT, and the version that was current
+// becomes AWSPREVIOUS. Rotate blocks until the rotation is done; run it in a goroutine to read the
+// secret during the rotation. It returns the VersionId of the new version.
+func (c *Client) Rotate(newValues map[string]string, opts ...RotateOption) string {
+	var o rotateOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	time.Sleep(o.startAfter)
+
+	c.mu.Lock()
+	versionID := c.addVersion(marshal(newValues))
+	c.stages[stagePending] = versionID
+	c.mu.Unlock()
+	if o.onStage != nil {
+		o.onStage(stagePending)
+	}
+
+	time.Sleep(o.pendingFor)
+
+	c.mu.Lock()
+	c.promote(versionID)
+	c.lastRotated = c.lastChanged
+	c.mu.Unlock()
+	if o.onStage != nil {
+		o.onStage(stageCurrent)
+	}
+	return versionID
+}
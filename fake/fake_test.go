@@ -0,0 +1,57 @@
+package fake_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/janduursma/aws-secretsmanager-wrapper-go/fake"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Rotate(t *testing.T) {
+	client := fake.NewClient(map[string]string{"DB_PASSWORD": "old"})
+	ctx := context.Background()
+	secretsManager, err := secretsmanagerWrapper.NewBuilder().
+		Region("us-test-1").
+		Secret("test-secret").
+		Options(
+			secretsmanagerWrapper.WithSecretsManagerClient(client),
+			secretsmanagerWrapper.WithoutCacheEncryption(),
+			secretsmanagerWrapper.WithCacheTTL(time.Millisecond),
+		).
+		Build(ctx)
+	require.NoError(t, err)
+
+	var stages []string
+	versionID := client.Rotate(map[string]string{"DB_PASSWORD": "new"},
+		fake.RotatePendingFor(time.Millisecond),
+		fake.RotateOnStage(func(stage string) {
+			stages = append(stages, stage)
+			if stage == secretsmanagerWrapper.VersionStagePending {
+				// While pending, readers still get the old value, and can dual-read the new one.
+				val, err := secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+				require.NoError(t, err)
+				require.Equal(t, "old", val)
+				val, err = secretsManager.GetVersion(ctx, "DB_PASSWORD", secretsmanagerWrapper.VersionStagePending)
+				require.NoError(t, err)
+				require.Equal(t, "new", val)
+			}
+		}),
+	)
+	require.Equal(t, []string{secretsmanagerWrapper.VersionStagePending, secretsmanagerWrapper.VersionStageCurrent}, stages)
+
+	time.Sleep(2 * time.Millisecond)
+	val, err := secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "new", val)
+	require.Equal(t, versionID, secretsManager.Version())
+	val, err = secretsManager.GetVersion(ctx, "DB_PASSWORD", secretsmanagerWrapper.VersionStagePrevious)
+	require.NoError(t, err)
+	require.Equal(t, "old", val)
+
+	// The pending version is gone after the rotation.
+	_, err = secretsManager.GetVersion(ctx, "DB_PASSWORD", secretsmanagerWrapper.VersionStagePending)
+	require.Error(t, err)
+}
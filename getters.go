@@ -0,0 +1,125 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetString retrieves the value for the given key. It is the same as Get, and exists alongside
+// the typed getters such as GetInt.
+func (s *SecretsManager) GetString(key string) (string, error) {
+	return s.Get(key)
+}
+
+// GetInt retrieves the value for the given key as a decimal integer, such as a port or pool size.
+func (s *SecretsManager) GetInt(key string) (int, error) {
+	return getParsed(s, key, "an integer", strconv.Atoi)
+}
+
+// GetBool retrieves the value for the given key as a boolean. It accepts the values of
+// strconv.ParseBool, such as "true", "false", "1" and "0".
+func (s *SecretsManager) GetBool(key string) (bool, error) {
+	return getParsed(s, key, "a boolean", strconv.ParseBool)
+}
+
+// GetDuration retrieves the value for the given key as a duration in the format of
+// time.ParseDuration, such as "30s" or "1h30m".
+func (s *SecretsManager) GetDuration(key string) (time.Duration, error) {
+	return getParsed(s, key, "a duration", time.ParseDuration)
+}
+
+// getParsed retrieves the value for the given key, trimmed of surrounding whitespace, and parses
+// it with parse. A parse error names the key, the secret and what the value should be.
+func getParsed[T any](s *SecretsManager, key, what string, parse func(string) (T, error)) (T, error) {
+	var zero T
+	val, err := s.GetWithContext(context.Background(), key)
+	if err != nil {
+		return zero, err
+	}
+	v, err := parse(strings.TrimSpace(val))
+	if err != nil {
+		return zero, fmt.Errorf("key %q in secret %q is not %s: %w", key, s.secretName, what, err)
+	}
+	return v, nil
+}
+
+// GetStringSlice retrieves a list value for the given key, such as allowed origins or broker
+// addresses. The value can be a JSON array of strings, or a comma-separated string. Elements are
+// trimmed of surrounding whitespace, and empty elements are dropped.
+func (s *SecretsManager) GetStringSlice(key string) ([]string, error) {
+	val, err := s.GetWithContext(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	list, err := parseStringSlice(val)
+	if err != nil {
+		return nil, fmt.Errorf("key %q in secret %q is not a list: %w", key, s.secretName, err)
+	}
+	return list, nil
+}
+
+// parseStringSlice parses a JSON array of strings or a comma-separated string.
+func parseStringSlice(val string) ([]string, error) {
+	var elems []string
+	if strings.HasPrefix(strings.TrimSpace(val), "[") {
+		if err := json.Unmarshal([]byte(val), &elems); err != nil {
+			return nil, err
+		}
+	} else {
+		elems = strings.Split(val, ",")
+	}
+	list := make([]string, 0, len(elems))
+	for _, elem := range elems {
+		if elem = strings.TrimSpace(elem); elem != "" {
+			list = append(list, elem)
+		}
+	}
+	return list, nil
+}
+
+// base64Encodings are the encodings GetBase64Bytes accepts, in the order they are tried.
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// GetBase64Bytes retrieves the base64-encoded value for the given key, such as an encryption key,
+// and returns it decoded. Both the standard and the URL-safe alphabet are accepted, with or
+// without padding. Surrounding whitespace is ignored.
+func (s *SecretsManager) GetBase64Bytes(key string) ([]byte, error) {
+	val, err := s.GetWithContext(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	val = strings.TrimSpace(val)
+	for _, enc := range base64Encodings {
+		if b, err := enc.DecodeString(val); err == nil {
+			return b, nil
+		}
+	}
+	// Report the error of the standard encoding, which is the most common one.
+	_, err = base64.StdEncoding.DecodeString(val)
+	return nil, fmt.Errorf("key %q in secret %q is not valid base64: %w", key, s.secretName, err)
+}
+
+// GetHexBytes retrieves the hex-encoded value for the given key, such as an HMAC secret, and
+// returns it decoded. Upper and lower case digits are accepted. Surrounding whitespace is ignored.
+func (s *SecretsManager) GetHexBytes(key string) ([]byte, error) {
+	val, err := s.GetWithContext(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := hex.DecodeString(strings.TrimSpace(val))
+	if err != nil {
+		return nil, fmt.Errorf("key %q in secret %q is not valid hex: %w", key, s.secretName, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,112 @@
+package secretsmanager_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_GetStringSlice(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	secretJSON, _ := json.Marshal(map[string]string{
+		"ALLOWED_ORIGINS": ` https://a.example.com, https://b.example.com ,,`,
+		"BROKERS":         `["broker-1:9092", " broker-2:9092 "]`,
+		"EMPTY":           "",
+		"INVALID":         `["broker-1:9092"`,
+	})
+	smMock.secretValue.Store(string(secretJSON))
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	list, err := secretsManager.GetStringSlice("ALLOWED_ORIGINS")
+	require.NoError(t, err)
+	require.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, list)
+
+	list, err = secretsManager.GetStringSlice("BROKERS")
+	require.NoError(t, err)
+	require.Equal(t, []string{"broker-1:9092", "broker-2:9092"}, list)
+
+	list, err = secretsManager.GetStringSlice("EMPTY")
+	require.NoError(t, err)
+	require.Empty(t, list)
+
+	_, err = secretsManager.GetStringSlice("INVALID")
+	require.ErrorContains(t, err, "is not a list")
+
+	_, err = secretsManager.GetStringSlice("MISSING")
+	require.Error(t, err)
+}
+
+func TestSecretsManager_GetBase64Bytes(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	secretJSON, _ := json.Marshal(map[string]string{
+		"STD":     "+/+/AA==",
+		"RAW_URL": "-_-_AA",
+		"INVALID": "not base64!",
+	})
+	smMock.secretValue.Store(string(secretJSON))
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	for _, key := range []string{"STD", "RAW_URL"} {
+		b, err := secretsManager.GetBase64Bytes(key)
+		require.NoError(t, err)
+		require.Equal(t, []byte{0xfb, 0xff, 0xbf, 0x00}, b)
+	}
+
+	_, err := secretsManager.GetBase64Bytes("INVALID")
+	require.ErrorContains(t, err, `key "INVALID" in secret "test-secret" is not valid base64`)
+}
+
+func TestSecretsManager_GetHexBytes(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"HMAC_KEY":" DEADbeef\n","INVALID":"xyz"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	b, err := secretsManager.GetHexBytes("HMAC_KEY")
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, b)
+
+	_, err = secretsManager.GetHexBytes("INVALID")
+	require.ErrorContains(t, err, `key "INVALID" in secret "test-secret" is not valid hex`)
+}
+
+func TestSecretsManager_TypedGetters(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	secretJSON, _ := json.Marshal(map[string]string{
+		"NAME":    "app",
+		"PORT":    " 5432 ",
+		"ENABLED": "true",
+		"TIMEOUT": "1m30s",
+		"INVALID": "abc",
+	})
+	smMock.secretValue.Store(string(secretJSON))
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	name, err := secretsManager.GetString("NAME")
+	require.NoError(t, err)
+	require.Equal(t, "app", name)
+
+	port, err := secretsManager.GetInt("PORT")
+	require.NoError(t, err)
+	require.Equal(t, 5432, port)
+
+	enabled, err := secretsManager.GetBool("ENABLED")
+	require.NoError(t, err)
+	require.True(t, enabled)
+
+	timeout, err := secretsManager.GetDuration("TIMEOUT")
+	require.NoError(t, err)
+	require.Equal(t, 90*time.Second, timeout)
+
+	_, err = secretsManager.GetInt("INVALID")
+	require.ErrorContains(t, err, `key "INVALID" in secret "test-secret" is not an integer`)
+	_, err = secretsManager.GetBool("INVALID")
+	require.ErrorContains(t, err, `key "INVALID" in secret "test-secret" is not a boolean`)
+	_, err = secretsManager.GetDuration("INVALID")
+	require.ErrorContains(t, err, `key "INVALID" in secret "test-secret" is not a duration`)
+
+	_, err = secretsManager.GetInt("MISSING")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+}
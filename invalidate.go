@@ -0,0 +1,99 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoPreviousValue is returned by GetPreviousCached for keys without a value dropped by
+// Invalidate or InvalidateAll within the tombstone grace period.
+var ErrNoPreviousValue = errors.New("no previous cached value")
+
+// tombstone is a cached value dropped by Invalidate or InvalidateAll, see WithTombstones.
+type tombstone struct {
+	encryptedValue string
+	invalidatedAt  time.Time
+}
+
+// WithTombstones keeps the values dropped by Invalidate and InvalidateAll for grace, still
+// encrypted, so they can be read with GetPreviousCached. This allows rolling back by hand when the
+// value fetched after an invalidation turns out to be broken.
+func WithTombstones(grace time.Duration) Option {
+	return func(s *SecretsManager) {
+		s.tombstoneGrace = grace
+	}
+}
+
+// Invalidate drops the cached value for key, so the next read of it fetches the secret from AWS.
+// With WithTombstones, the dropped value is kept for GetPreviousCached.
+func (s *SecretsManager) Invalidate(key string) {
+	s.cacheLock.Lock()
+	defer s.cacheLock.Unlock()
+	if cs, ok := s.cache[key]; ok {
+		s.addTombstoneLocked(key, cs, time.Now())
+	}
+	delete(s.cache, key)
+	// The key is no longer known to be present or missing.
+	s.keySet = cachedSecret{}
+}
+
+// InvalidateAll drops all cached values, so the next read fetches the secret from AWS.
+// With WithTombstones, the dropped values are kept for GetPreviousCached.
+func (s *SecretsManager) InvalidateAll() {
+	if s.tombstoneGrace > 0 {
+		now := time.Now()
+		s.cacheLock.Lock()
+		for key, cs := range s.cache {
+			s.addTombstoneLocked(key, cs, now)
+		}
+		s.cacheLock.Unlock()
+	}
+	s.purgeCache()
+}
+
+// GetPreviousCached retrieves the value for key that was dropped from the cache by the last
+// Invalidate or InvalidateAll, within the grace period set with WithTombstones. It never fetches
+// the secret. Without such a value, it returns ErrNoPreviousValue.
+func (s *SecretsManager) GetPreviousCached(key string) (string, error) {
+	ctx := context.Background()
+	if err := s.checkAccess(ctx, key); err != nil {
+		return "", err
+	}
+	s.cacheLock.RLock()
+	t, ok := s.tombstones[key]
+	s.cacheLock.RUnlock()
+	if !ok || time.Since(t.invalidatedAt) >= s.tombstoneGrace {
+		return "", fmt.Errorf("%s: %w", key, ErrNoPreviousValue)
+	}
+	return s.cacheCipher().Decrypt(ctx, t.encryptedValue)
+}
+
+// addTombstoneLocked keeps cs, dropped from the cache under key, for GetPreviousCached, and drops
+// expired tombstones. The caller must hold cacheLock.
+func (s *SecretsManager) addTombstoneLocked(key string, cs cachedSecret, now time.Time) {
+	if s.tombstoneGrace <= 0 {
+		return
+	}
+	for k, t := range s.tombstones {
+		if now.Sub(t.invalidatedAt) >= s.tombstoneGrace {
+			delete(s.tombstones, k)
+		}
+	}
+	if s.tombstones == nil {
+		s.tombstones = make(map[string]tombstone)
+	}
+	s.tombstones[key] = tombstone{encryptedValue: cs.encryptedValue, invalidatedAt: now}
+}
+
+// Refresh fetches the secret from AWS right away and replaces the cached values, e.g. from a
+// deployment hook that knows a rotation just happened. Watchers poll right away afterwards.
+// If the fetch fails, the cached values are kept.
+func (s *SecretsManager) Refresh(ctx context.Context) error {
+	err := s.refresh(ctx)
+	if err == nil {
+		s.pokeWatchers()
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_Invalidate(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_USER":"admin","DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+	_, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+
+	// Only the invalidated key is fetched again.
+	smMock.secretValue.Store(`{"DB_USER":"admin","DB_PASSWORD":"rotated"}`)
+	secretsManager.Invalidate("DB_PASSWORD")
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "rotated", val)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+	_, err = secretsManager.Get("DB_USER")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+
+	secretsManager.InvalidateAll()
+	_, err = secretsManager.Get("DB_USER")
+	require.NoError(t, err)
+	require.Equal(t, int32(3), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_Refresh(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+	_, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+
+	smMock.secretValue.Store(`{"DB_PASSWORD":"rotated"}`)
+	require.NoError(t, secretsManager.Refresh(context.Background()))
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+
+	// The refreshed value is served from the cache.
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "rotated", val)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_GetPreviousCached(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_USER":"admin","DB_PASSWORD":"password"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+		secretsmanagerWrapper.WithTombstones(50*time.Millisecond),
+	)
+	require.NoError(t, err)
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	_, err = secretsManager.GetPreviousCached("DB_PASSWORD")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrNoPreviousValue)
+
+	// The value dropped by Invalidate stays available after the new one was fetched.
+	smMock.secretValue.Store(`{"DB_USER":"admin","DB_PASSWORD":"broken"}`)
+	secretsManager.Invalidate("DB_PASSWORD")
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "broken", val)
+	val, err = secretsManager.GetPreviousCached("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	secretsManager.InvalidateAll()
+	val, err = secretsManager.GetPreviousCached("DB_USER")
+	require.NoError(t, err)
+	require.Equal(t, "admin", val)
+	val, err = secretsManager.GetPreviousCached("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "broken", val)
+
+	// Tombstones expire after the grace period.
+	time.Sleep(60 * time.Millisecond)
+	_, err = secretsManager.GetPreviousCached("DB_USER")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrNoPreviousValue)
+}
+
+func TestSecretsManager_GetPreviousCached_Disabled(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+	_, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+
+	secretsManager.Invalidate("DB_PASSWORD")
+	_, err = secretsManager.GetPreviousCached("DB_PASSWORD")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrNoPreviousValue)
+}
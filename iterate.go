@@ -0,0 +1,104 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// LazyValue is a cached secret value that is only decrypted when Value is called.
+type LazyValue struct {
+	ctx            context.Context
+	cipher         CacheCipher
+	key            string
+	encryptedValue string
+}
+
+// Value decrypts and returns the secret value.
+func (v LazyValue) Value() (string, error) {
+	plaintext, err := v.cipher.Decrypt(v.ctx, v.encryptedValue)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt cached value for %s: %w", v.key, err)
+	}
+	return plaintext, nil
+}
+
+// Iterate calls fn for each key of the secret in sorted order, until fn returns false.
+// Values are only decrypted when fn calls their Value method, so scanning for a few keys
+// among many only pays for decrypting those. The secret is refreshed first if the cache
+// is expired.
+func (s *SecretsManager) Iterate(ctx context.Context, fn func(key string, value LazyValue) bool) error {
+	return s.iterate(ctx, nil, fn)
+}
+
+// iterate implements Iterate for the keys that include reports true for, or all keys if include
+// is nil. The access hook is run for every key before fn is called for any, so a rejected read
+// fails the whole iteration.
+func (s *SecretsManager) iterate(ctx context.Context, include func(key string) bool, fn func(key string, value LazyValue) bool) error {
+	if err := s.ensureFresh(ctx); err != nil {
+		return err
+	}
+
+	// Take a snapshot, so fn runs without holding the lock.
+	cipher := s.cacheCipher()
+	s.cacheLock.RLock()
+	values := make([]LazyValue, 0, len(s.cache))
+	for k, cs := range s.cache {
+		if include == nil || include(k) {
+			values = append(values, LazyValue{ctx: ctx, cipher: cipher, key: k, encryptedValue: cs.encryptedValue})
+		}
+	}
+	s.cacheLock.RUnlock()
+	sort.Slice(values, func(i, j int) bool { return values[i].key < values[j].key })
+
+	for _, v := range values {
+		if err := s.checkAccess(ctx, v.key); err != nil {
+			return err
+		}
+	}
+	for _, v := range values {
+		if !fn(v.key, v) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Keys returns the keys of the secret, sorted in byte-wise lexical order. The order only depends
+// on the keys themselves, so it is stable across calls and processes and can be used for hashing.
+// The secret is refreshed first if the cache is expired.
+func (s *SecretsManager) Keys(ctx context.Context) ([]string, error) {
+	var keys []string
+	err := s.Iterate(ctx, func(key string, _ LazyValue) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetAll retrieves all key–value pairs of the secret, decrypting every value.
+// As with any Go map, ranging over the result is unordered; range over Keys for a stable order.
+// The secret is refreshed first if the cache is expired.
+func (s *SecretsManager) GetAll(ctx context.Context) (map[string]string, error) {
+	return s.getAll(ctx, nil)
+}
+
+// getAll implements GetAll for the keys that include reports true for, or all keys if include is nil.
+func (s *SecretsManager) getAll(ctx context.Context, include func(key string) bool) (map[string]string, error) {
+	values := make(map[string]string)
+	var err error
+	iterErr := s.iterate(ctx, include, func(key string, value LazyValue) bool {
+		values[key], err = value.Value()
+		return err == nil
+	})
+	if iterErr != nil {
+		return nil, iterErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
@@ -0,0 +1,106 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mockKMSClientCountingDecrypt simulates a KMS client that counts Decrypt calls.
+type mockKMSClientCountingDecrypt struct {
+	mockKMSClient
+	decryptCount int32
+}
+
+func (m *mockKMSClientCountingDecrypt) Decrypt(ctx context.Context, input *kms.DecryptInput, opts ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	atomic.AddInt32(&m.decryptCount, 1)
+	return m.mockKMSClient.Decrypt(ctx, input, opts...)
+}
+
+func TestSecretsManager_Iterate(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"C": "c", "A": "a", "B": "b", "D": "d"})
+	require.NoError(t, err)
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+	kmsMock := &mockKMSClientCountingDecrypt{}
+
+	secretsManager := newSecretsManagerForTest(t, smMock, kmsMock, time.Minute)
+
+	// Keys are visited in sorted order, and iteration stops when fn returns false.
+	var keys []string
+	var found string
+	err = secretsManager.Iterate(context.Background(), func(key string, value secretsmanagerWrapper.LazyValue) bool {
+		keys = append(keys, key)
+		if key != "B" {
+			return true
+		}
+		found, err = value.Value()
+		require.NoError(t, err)
+		return false
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"A", "B"}, keys)
+	require.Equal(t, "b", found)
+
+	// Only the value that was read has been decrypted.
+	require.Equal(t, int32(1), atomic.LoadInt32(&kmsMock.decryptCount))
+}
+
+func TestSecretsManager_Iterate_RemovedKeys(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"A": "a", "B": "b"})
+	require.NoError(t, err)
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, 50*time.Millisecond)
+	_, err = secretsManager.Get("B")
+	require.NoError(t, err)
+
+	// Remove a key from the secret and let the cache expire.
+	updatedJSON, err := json.Marshal(map[string]string{"A": "a"})
+	require.NoError(t, err)
+	smMock.secretValue.Store(string(updatedJSON))
+	time.Sleep(60 * time.Millisecond)
+
+	var keys []string
+	err = secretsManager.Iterate(context.Background(), func(key string, _ secretsmanagerWrapper.LazyValue) bool {
+		keys = append(keys, key)
+		return true
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"A"}, keys)
+
+	_, err = secretsManager.Get("B")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+}
+
+func TestSecretsManager_KeysAndGetAll(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"b": "2", "B": "1", "a": "3", "_": "4"})
+	require.NoError(t, err)
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// Keys are sorted byte-wise, and the order is the same on every call.
+	for i := 0; i < 3; i++ {
+		keys, err := secretsManager.Keys(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, []string{"B", "_", "a", "b"}, keys)
+	}
+
+	values, err := secretsManager.GetAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"b": "2", "B": "1", "a": "3", "_": "4"}, values)
+
+	// Decryption failures are reported.
+	failing := newSecretsManagerForTest(t, smMock, &mockKMSClientDecryptFailure{}, time.Minute)
+	_, err = failing.GetAll(context.Background())
+	require.Error(t, err)
+}
@@ -3,16 +3,61 @@ package secretsmanager
 import (
 	"context"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	"github.com/janduursma/aws-secretsmanager-wrapper-go/awsapi"
 )
 
+// ErrKMSKeyDisabled is returned when the KMS key used to encrypt cached values is not enabled.
+var ErrKMSKeyDisabled = errors.New("KMS key is not enabled")
+
+// KMSKeyDescriber is implemented by KMS clients that can describe keys, such as the default client.
+// It is used to resolve and validate KMS aliases.
+type KMSKeyDescriber = awsapi.KMSKeyDescriber
+
+// isKMSAlias reports whether keyID is a KMS alias name or alias ARN.
+func isKMSAlias(keyID string) bool {
+	return strings.HasPrefix(keyID, "alias/") || strings.Contains(keyID, ":alias/")
+}
+
+// ResolveKMSKey resolves a KMS alias to the ARN of the key it points to.
+// It returns an error if the alias does not exist or the key is not enabled.
+func ResolveKMSKey(ctx context.Context, client KMSKeyDescriber, alias string) (string, error) {
+	out, err := client.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: &alias})
+	if err != nil {
+		var notFound *types.NotFoundException
+		if errors.As(err, &notFound) {
+			return "", fmt.Errorf("KMS alias %s does not exist: %w", alias, err)
+		}
+		return "", fmt.Errorf("failed to describe KMS alias %s: %w", alias, err)
+	}
+	if out.KeyMetadata == nil {
+		return "", fmt.Errorf("failed to describe KMS alias %s: no key metadata", alias)
+	}
+	if !out.KeyMetadata.Enabled {
+		return "", fmt.Errorf("KMS alias %s points to key %s in state %s: %w",
+			alias, aws.ToString(out.KeyMetadata.KeyId), out.KeyMetadata.KeyState, ErrKMSKeyDisabled)
+	}
+	return aws.ToString(out.KeyMetadata.Arn), nil
+}
+
 // EncryptValue uses AWS KMS to encrypt a plaintext string.
 // It returns a base64-encoded ciphertext.
 func EncryptValue(ctx context.Context, client KMSClient, keyID, plaintext string) (string, error) {
+	return encryptValue(ctx, client, keyID, plaintext, nil)
+}
+
+// encryptValue is EncryptValue with a KMS encryption context, which may be nil.
+func encryptValue(ctx context.Context, client KMSClient, keyID, plaintext string, encryptionContext map[string]string) (string, error) {
 	input := &kms.EncryptInput{
-		KeyId:     &keyID,
-		Plaintext: []byte(plaintext),
+		KeyId:             &keyID,
+		Plaintext:         []byte(plaintext),
+		EncryptionContext: encryptionContext,
 	}
 	result, err := client.Encrypt(ctx, input)
 	if err != nil {
@@ -24,13 +69,22 @@ func EncryptValue(ctx context.Context, client KMSClient, keyID, plaintext string
 
 // DecryptValue uses AWS KMS to decrypt a base64-encoded ciphertext.
 // It returns the plaintext string.
+// The KMS key is identified from the ciphertext.
 func DecryptValue(ctx context.Context, client KMSClient, ciphertextB64 string) (string, error) {
+	return decryptValue(ctx, client, "", ciphertextB64, nil)
+}
+
+// decryptValue is DecryptValue, restricted to the KMS key keyID unless it is empty, with a KMS
+// encryption context, which may be nil.
+func decryptValue(ctx context.Context, client KMSClient, keyID, ciphertextB64 string, encryptionContext map[string]string) (string, error) {
 	ciphertext, err := base64.StdEncoding.DecodeString(ciphertextB64)
 	if err != nil {
 		return "", err
 	}
 	input := &kms.DecryptInput{
-		CiphertextBlob: ciphertext,
+		CiphertextBlob:    ciphertext,
+		KeyId:             nonEmpty(keyID),
+		EncryptionContext: encryptionContext,
 	}
 	result, err := client.Decrypt(ctx, input)
 	if err != nil {
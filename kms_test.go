@@ -0,0 +1,206 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mockKMSDescribeClient simulates a KMS client that knows a fixed set of aliases.
+type mockKMSDescribeClient struct {
+	mockKMSClient
+	// keys maps aliases to the metadata of the key they point to.
+	keys map[string]*types.KeyMetadata
+	// encryptKeyID holds the KeyId of the last Encrypt request.
+	encryptKeyID string
+}
+
+func (m *mockKMSDescribeClient) DescribeKey(_ context.Context, input *kms.DescribeKeyInput, _ ...func(*kms.Options)) (*kms.DescribeKeyOutput, error) {
+	metadata, ok := m.keys[aws.ToString(input.KeyId)]
+	if !ok {
+		return nil, &types.NotFoundException{Message: aws.String("alias not found")}
+	}
+	return &kms.DescribeKeyOutput{KeyMetadata: metadata}, nil
+}
+
+func (m *mockKMSDescribeClient) Encrypt(ctx context.Context, input *kms.EncryptInput, opts ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	m.encryptKeyID = aws.ToString(input.KeyId)
+	return m.mockKMSClient.Encrypt(ctx, input, opts...)
+}
+
+func TestNewSecretsManager_KMSAlias(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"DB_PASSWORD": "password"})
+	require.NoError(t, err)
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+
+	keyARN := "arn:aws:kms:us-test-1:111122223333:key/1234abcd"
+	kmsMock := &mockKMSDescribeClient{keys: map[string]*types.KeyMetadata{
+		"alias/myapp":    {KeyId: aws.String("1234abcd"), Arn: aws.String(keyARN), Enabled: true, KeyState: types.KeyStateEnabled},
+		"alias/disabled": {KeyId: aws.String("5678efgh"), Enabled: false, KeyState: types.KeyStateDisabled},
+	}}
+
+	// The alias is resolved to the key ARN, which is used for encryption.
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "alias/myapp",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(kmsMock),
+	)
+	require.NoError(t, err)
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, keyARN, kmsMock.encryptKeyID)
+
+	// A missing alias fails at construction.
+	_, err = secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "alias/missing",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(kmsMock),
+	)
+	require.ErrorContains(t, err, "alias/missing does not exist")
+
+	// So does an alias pointing to a disabled key.
+	_, err = secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "alias/disabled",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(kmsMock),
+	)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrKMSKeyDisabled)
+}
+
+// mockKMSAliasClient simulates a KMS client with a single alias, which can be repointed to another key.
+// Ciphertexts are prefixed with the ID of the key that encrypted them.
+type mockKMSAliasClient struct {
+	mu sync.Mutex
+	// aliasTarget is the key ID the alias points to.
+	aliasTarget string
+}
+
+// resolve returns the key ID that keyID refers to.
+func (m *mockKMSAliasClient) resolve(keyID string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if keyID == "alias/myapp" {
+		return m.aliasTarget
+	}
+	return keyID
+}
+
+func (m *mockKMSAliasClient) Encrypt(_ context.Context, input *kms.EncryptInput, _ ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	return &kms.EncryptOutput{CiphertextBlob: append([]byte(m.resolve(aws.ToString(input.KeyId))+":"), input.Plaintext...)}, nil
+}
+
+func (m *mockKMSAliasClient) Decrypt(_ context.Context, input *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	keyID, plaintext, _ := strings.Cut(string(input.CiphertextBlob), ":")
+	if input.KeyId != nil && m.resolve(*input.KeyId) != keyID {
+		return nil, &types.IncorrectKeyException{Message: aws.String("incorrect key")}
+	}
+	return &kms.DecryptOutput{Plaintext: []byte(plaintext), KeyId: aws.String(keyID)}, nil
+}
+
+func TestSecretsManager_WithKMSDecryptKeys(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	kmsMock := &mockKMSAliasClient{aliasTarget: "old-key"}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "alias/myapp",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(kmsMock),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+		secretsmanagerWrapper.WithKMSDecryptKeys("old-key"),
+	)
+	require.NoError(t, err)
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+
+	// After the alias moved to the new key, values cached under the old key still decrypt.
+	kmsMock.mu.Lock()
+	kmsMock.aliasTarget = "new-key"
+	kmsMock.mu.Unlock()
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	// Keys that are neither the primary nor a candidate are rejected.
+	secretsManager, err = secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "alias/myapp",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(kmsMock),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+		secretsmanagerWrapper.WithKMSDecryptKeys("other-key"),
+	)
+	require.NoError(t, err)
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	kmsMock.mu.Lock()
+	kmsMock.aliasTarget = "newest-key"
+	kmsMock.mu.Unlock()
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.Error(t, err)
+}
+
+// mockKMSContextClient simulates a KMS client that binds ciphertexts to their encryption context.
+type mockKMSContextClient struct {
+	mu sync.Mutex
+	// contexts records the encryption contexts of all requests.
+	contexts []map[string]string
+}
+
+// contextPrefix serializes an encryption context.
+func contextPrefix(encryptionContext map[string]string) string {
+	data, _ := json.Marshal(encryptionContext)
+	return string(data) + "|"
+}
+
+func (m *mockKMSContextClient) record(encryptionContext map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.contexts = append(m.contexts, encryptionContext)
+}
+
+func (m *mockKMSContextClient) Encrypt(_ context.Context, input *kms.EncryptInput, _ ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	m.record(input.EncryptionContext)
+	return &kms.EncryptOutput{CiphertextBlob: append([]byte(contextPrefix(input.EncryptionContext)), input.Plaintext...)}, nil
+}
+
+func (m *mockKMSContextClient) Decrypt(_ context.Context, input *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	m.record(input.EncryptionContext)
+	plaintext, ok := strings.CutPrefix(string(input.CiphertextBlob), contextPrefix(input.EncryptionContext))
+	if !ok {
+		return nil, &types.InvalidCiphertextException{Message: aws.String("encryption context mismatch")}
+	}
+	return &kms.DecryptOutput{Plaintext: []byte(plaintext)}, nil
+}
+
+func TestSecretsManager_WithKMSEncryptionContext(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	kmsMock := &mockKMSContextClient{}
+	encryptionContext := map[string]string{"service": "billing"}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(kmsMock),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+		secretsmanagerWrapper.WithKMSEncryptionContext(encryptionContext),
+	)
+	require.NoError(t, err)
+
+	// Changes to the map after the option was applied have no effect.
+	encryptionContext["service"] = "other"
+
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	// Every Encrypt and Decrypt carried the context.
+	require.NotEmpty(t, kmsMock.contexts)
+	for _, c := range kmsMock.contexts {
+		require.Equal(t, map[string]string{"service": "billing"}, c)
+	}
+}
@@ -0,0 +1,71 @@
+package secretsmanager
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// Profiler label keys.
+const (
+	labelOperation  = "secretsmanager.operation"
+	labelSecretName = "secretsmanager.secret_name"
+)
+
+// WithProfilerLabels attaches pprof labels to the goroutines while they fetch the secret from AWS
+// Secrets Manager ("fetch"), and encrypt ("encrypt") or decrypt ("decrypt") cached values. CPU and
+// goroutine profiles can then attribute the time spent to this package and the secret, by the
+// labels secretsmanager.operation and secretsmanager.secret_name. The labels are also set on the
+// context passed to the AWS clients. Labeling allocates, so it is off by default.
+func WithProfilerLabels() Option {
+	return func(s *SecretsManager) {
+		s.profilerLabels = true
+	}
+}
+
+// withLabels calls f with the profiler labels of operation, if WithProfilerLabels is set.
+func (s *SecretsManager) withLabels(ctx context.Context, operation string, f func(ctx context.Context)) {
+	if !s.profilerLabels {
+		f(ctx)
+		return
+	}
+	pprof.Do(ctx, pprof.Labels(labelOperation, operation, labelSecretName, s.secretName), f)
+}
+
+// labeledCipher is a CacheCipher that attaches the profiler labels to its operations.
+type labeledCipher struct {
+	c CacheCipher
+	s *SecretsManager
+}
+
+// Encrypt implements CacheCipher.
+func (c labeledCipher) Encrypt(ctx context.Context, plaintext string) (ciphertext string, err error) {
+	c.s.withLabels(ctx, "encrypt", func(ctx context.Context) {
+		ciphertext, err = c.c.Encrypt(ctx, plaintext)
+	})
+	return ciphertext, err
+}
+
+// Decrypt implements CacheCipher.
+func (c labeledCipher) Decrypt(ctx context.Context, ciphertext string) (plaintext string, err error) {
+	c.s.withLabels(ctx, "decrypt", func(ctx context.Context) {
+		plaintext, err = c.c.Decrypt(ctx, ciphertext)
+	})
+	return plaintext, err
+}
+
+// encrypter implements batchEncrypter if the wrapped cipher does.
+func (c labeledCipher) encrypter(ctx context.Context) (CacheCipher, error) {
+	b, ok := c.c.(batchEncrypter)
+	if !ok {
+		return c, nil
+	}
+	var enc CacheCipher
+	var err error
+	c.s.withLabels(ctx, "encrypt", func(ctx context.Context) {
+		enc, err = b.encrypter(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return labeledCipher{c: enc, s: c.s}, nil
+}
@@ -0,0 +1,77 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// labelRecorder records the profiler labels of the calls to the AWS clients.
+type labelRecorder struct {
+	mu     sync.Mutex
+	labels []string
+}
+
+func (r *labelRecorder) record(ctx context.Context, call string) {
+	op, _ := pprof.Label(ctx, "secretsmanager.operation")
+	name, _ := pprof.Label(ctx, "secretsmanager.secret_name")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.labels = append(r.labels, call+": "+op+" "+name)
+}
+
+type labelRecordingSecretsManagerClient struct {
+	*mockSecretsManagerClient
+	r *labelRecorder
+}
+
+func (c labelRecordingSecretsManagerClient) GetSecretValue(ctx context.Context, input *awsSecretsManager.GetSecretValueInput, optFns ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
+	c.r.record(ctx, "GetSecretValue")
+	return c.mockSecretsManagerClient.GetSecretValue(ctx, input, optFns...)
+}
+
+type labelRecordingKMSClient struct {
+	mockKMSClient
+	r *labelRecorder
+}
+
+func (c labelRecordingKMSClient) Encrypt(ctx context.Context, input *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	c.r.record(ctx, "Encrypt")
+	return c.mockKMSClient.Encrypt(ctx, input, optFns...)
+}
+
+func (c labelRecordingKMSClient) Decrypt(ctx context.Context, input *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	c.r.record(ctx, "Decrypt")
+	return c.mockKMSClient.Decrypt(ctx, input, optFns...)
+}
+
+func TestSecretsManager_WithProfilerLabels(t *testing.T) {
+	r := &labelRecorder{}
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(labelRecordingSecretsManagerClient{smMock, r}),
+		secretsmanagerWrapper.WithKMSClient(labelRecordingKMSClient{r: r}),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+		secretsmanagerWrapper.WithProfilerLabels(),
+	)
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+	// The value and the raw payload are encrypted separately.
+	require.Equal(t, []string{
+		"GetSecretValue: fetch test-secret",
+		"Encrypt: encrypt test-secret",
+		"Encrypt: encrypt test-secret",
+		"Decrypt: decrypt test-secret",
+	}, r.labels)
+}
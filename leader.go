@@ -0,0 +1,78 @@
+package secretsmanager
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// WithLeaderCheck limits background polling to the elected leader of a clustered deployment.
+// Watchers and StartAutoRefresh skip their periodic polls while isLeader returns false. Instead,
+// followers check the version of the secret with DescribeSecret on each interval, which reads no
+// secret value and needs no KMS call, and refresh the secret and run their watchers only when a
+// new version is current. One check per interval serves all watchers and the auto-refresher of a
+// SecretsManager. Followers whose client does not implement SecretDescriber do not notice
+// new versions in the background. Followers still fetch on reads once their cached values expire,
+// and watchers still poll on Refresh and on events passed to HandleSecretEvent or received by
+// StartEventListener, so followers can pick up rotations from events the leader does not have to
+// relay.
+func WithLeaderCheck(isLeader func() bool) Option {
+	return func(s *SecretsManager) {
+		s.isLeader = isLeader
+	}
+}
+
+// shouldPoll reports whether the periodic background polls should run, as set by WithLeaderCheck.
+func (s *SecretsManager) shouldPoll() bool {
+	return s.isLeader == nil || s.isLeader()
+}
+
+// refreshIfNewVersion refreshes the secret and makes all watchers poll if DescribeSecret reports
+// a version other than the cached one, for followers that skip the periodic polls. The check is
+// made once per interval for the whole instance: callers that come while a check is running share
+// it, and callers within half an interval of the last check, which allows for ticker drift, skip it.
+func (s *SecretsManager) refreshIfNewVersion(ctx context.Context, interval time.Duration) {
+	_, _, _ = s.versionCheckGroup.Do(s.secretName, func() (any, error) {
+		s.versionCheckMu.Lock()
+		if time.Since(s.versionCheckedAt) < interval/2 {
+			s.versionCheckMu.Unlock()
+			return nil, nil
+		}
+		s.versionCheckedAt = time.Now()
+		s.versionCheckMu.Unlock()
+
+		if !s.hasNewVersion(ctx) {
+			return nil, nil
+		}
+		if err := s.Refresh(ctx); err != nil {
+			s.log().Error(ctx, "refresh after new secret version failed", "secret", s.secretName, "error", err)
+		}
+		return nil, nil
+	})
+}
+
+// hasNewVersion reports whether the version of the secret with the staging label that s reads
+// differs from the cached version. Pinned versions never change, and clients that cannot describe
+// secrets report no new version.
+func (s *SecretsManager) hasNewVersion(ctx context.Context) bool {
+	cached := s.Version()
+	describer, ok := s.secretsManagerAPI().(SecretDescriber)
+	if !ok || cached == "" || s.pinnedVersionID != "" {
+		return false
+	}
+	out, err := describer.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: &s.secretName})
+	if err != nil {
+		s.log().Debug(ctx, "failed to check secret version", "secret", s.secretName, "error", err)
+		return false
+	}
+	stage := cmp.Or(s.versionStage, VersionStageCurrent)
+	for versionID, stages := range out.VersionIdsToStages {
+		if slices.Contains(stages, stage) {
+			return versionID != cached
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_WithLeaderCheck(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"initialPassword"}`)
+	var leader atomic.Bool
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+		secretsmanagerWrapper.WithLeaderCheck(leader.Load),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	secretsManager.StartAutoRefresh(ctx, 10*time.Millisecond)
+	var changes atomic.Int32
+	secretsManager.Watch(ctx, "DB_PASSWORD", 10*time.Millisecond, func(string) {
+		changes.Add(1)
+	})
+
+	// A follower only fetches for the first poll of the watcher.
+	time.Sleep(60 * time.Millisecond)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+
+	// Refresh still reaches the watchers of a follower.
+	smMock.secretValue.Store(`{"DB_PASSWORD":"newPassword"}`)
+	require.NoError(t, secretsManager.Refresh(ctx))
+	require.Eventually(t, func() bool { return changes.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	// Once elected, the instance polls on every interval.
+	calls := atomic.LoadInt32(&smMock.callCount)
+	leader.Store(true)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&smMock.callCount) >= calls+2
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSecretsManager_WithLeaderCheck_FollowerNoticesNewVersion(t *testing.T) {
+	// The client reports the version of the current value through DescribeSecret.
+	smMock := &mockPrimarySecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"initialPassword"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+		secretsmanagerWrapper.WithLeaderCheck(func() bool { return false }),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan string, 1)
+	secretsManager.Watch(ctx, "DB_PASSWORD", 10*time.Millisecond, func(newVal string) {
+		changed <- newVal
+	})
+
+	// While the version is unchanged, the follower does not fetch the secret.
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+
+	// A new version is noticed without fetching on every interval, and reaches the watcher.
+	smMock.secretValue.Store(`{"DB_PASSWORD":"newPassword"}`)
+	select {
+	case val := <-changed:
+		require.Equal(t, "newPassword", val)
+	case <-time.After(time.Second):
+		t.Fatal("watcher of a follower did not see the new version")
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_WithLeaderCheck_SharedVersionCheck(t *testing.T) {
+	smMock := &mockPrimarySecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password","DB_USER":"admin"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+		secretsmanagerWrapper.WithLeaderCheck(func() bool { return false }),
+	)
+	require.NoError(t, err)
+
+	// Several watchers and the auto-refresher share one version check per interval.
+	const interval = 40 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	for range 5 {
+		secretsManager.Watch(ctx, "DB_PASSWORD", interval, func(string) {})
+	}
+	secretsManager.StartAutoRefresh(ctx, interval)
+	time.Sleep(10*interval + interval/2)
+	cancel()
+
+	checks := atomic.LoadInt32(&smMock.describeCount)
+	require.GreaterOrEqual(t, checks, int32(5))
+	require.LessOrEqual(t, checks, int32(11))
+}
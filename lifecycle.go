@@ -0,0 +1,117 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/janduursma/aws-secretsmanager-wrapper-go/awsapi"
+)
+
+// LifecycleClient is implemented by Secrets Manager clients that can create and delete secrets,
+// such as the default client.
+type LifecycleClient = awsapi.LifecycleClient
+
+// createOptions holds the settings of CreateSecret.
+type createOptions struct {
+	kmsKeyID    string
+	description string
+	tags        map[string]string
+}
+
+// CreateOption defines a functional option for configuring CreateSecret.
+type CreateOption func(*createOptions)
+
+// CreateWithKMSKey encrypts the new secret with the given KMS key instead of aws/secretsmanager.
+func CreateWithKMSKey(keyID string) CreateOption {
+	return func(o *createOptions) {
+		o.kmsKeyID = keyID
+	}
+}
+
+// CreateWithDescription sets the description of the new secret.
+func CreateWithDescription(description string) CreateOption {
+	return func(o *createOptions) {
+		o.description = description
+	}
+}
+
+// CreateWithTags attaches the given tags to the new secret.
+func CreateWithTags(tags map[string]string) CreateOption {
+	return func(o *createOptions) {
+		if o.tags == nil {
+			o.tags = make(map[string]string, len(tags))
+		}
+		for k, v := range tags {
+			o.tags[k] = v
+		}
+	}
+}
+
+// CreateSecret creates a secret named name, holding initialValues as a JSON object.
+// It requires a Secrets Manager client that implements LifecycleClient.
+func (s *SecretsManager) CreateSecret(ctx context.Context, name string, initialValues map[string]string, opts ...CreateOption) error {
+	var o createOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	client, ok := s.secretsManagerAPI().(LifecycleClient)
+	if !ok {
+		return fmt.Errorf("create %s: Secrets Manager client cannot create secrets", name)
+	}
+
+	if initialValues == nil {
+		initialValues = map[string]string{}
+	}
+	payload, err := json.Marshal(initialValues)
+	if err != nil {
+		return err
+	}
+	input := &secretsmanager.CreateSecretInput{
+		Name:         &name,
+		SecretString: aws.String(string(payload)),
+		KmsKeyId:     nonEmpty(o.kmsKeyID),
+		Description:  nonEmpty(o.description),
+	}
+	// Sort the tags, so requests are deterministic.
+	keys := make([]string, 0, len(o.tags))
+	for k := range o.tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		input.Tags = append(input.Tags, types.Tag{Key: aws.String(k), Value: aws.String(o.tags[k])})
+	}
+
+	if _, err := client.CreateSecret(ctx, input); err != nil {
+		return fmt.Errorf("create %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteSecret deletes the secret named name. Unless forceWithoutRecovery is set, the secret is
+// scheduled for deletion after the default recovery window of 30 days, during which it can be
+// restored. If name is the secret of this SecretsManager, the local cache is purged.
+// It requires a Secrets Manager client that implements LifecycleClient.
+func (s *SecretsManager) DeleteSecret(ctx context.Context, name string, forceWithoutRecovery bool) error {
+	client, ok := s.secretsManagerAPI().(LifecycleClient)
+	if !ok {
+		return fmt.Errorf("delete %s: Secrets Manager client cannot delete secrets", name)
+	}
+
+	input := &secretsmanager.DeleteSecretInput{SecretId: &name}
+	if forceWithoutRecovery {
+		input.ForceDeleteWithoutRecovery = aws.Bool(true)
+	}
+	if _, err := client.DeleteSecret(ctx, input); err != nil {
+		return fmt.Errorf("delete %s: %w", name, err)
+	}
+	if name == s.secretName {
+		s.purgeCache()
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLifecycleClient simulates a Secrets Manager client that can also create and delete secrets.
+type mockLifecycleClient struct {
+	mockSecretsByIDClient
+	// createInput and deleteInput hold the last requests.
+	createInput *awsSecretsManager.CreateSecretInput
+	deleteInput *awsSecretsManager.DeleteSecretInput
+}
+
+// CreateSecret simulates the AWS SDK CreateSecret method.
+func (m *mockLifecycleClient) CreateSecret(_ context.Context, input *awsSecretsManager.CreateSecretInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.CreateSecretOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	name := aws.ToString(input.Name)
+	if _, ok := m.secrets[name]; ok {
+		return nil, &types.ResourceExistsException{Message: aws.String("secret already exists")}
+	}
+	m.createInput = input
+	m.secrets[name] = aws.ToString(input.SecretString)
+	return &awsSecretsManager.CreateSecretOutput{Name: input.Name}, nil
+}
+
+// DeleteSecret simulates the AWS SDK DeleteSecret method.
+func (m *mockLifecycleClient) DeleteSecret(_ context.Context, input *awsSecretsManager.DeleteSecretInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.DeleteSecretOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleteInput = input
+	delete(m.secrets, aws.ToString(input.SecretId))
+	return &awsSecretsManager.DeleteSecretOutput{Name: input.SecretId}, nil
+}
+
+func TestSecretsManager_CreateSecret(t *testing.T) {
+	smMock := &mockLifecycleClient{mockSecretsByIDClient: mockSecretsByIDClient{secrets: map[string]string{}}}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	err := secretsManager.CreateSecret(context.Background(), "new-secret", map[string]string{"DB_USER": "admin"},
+		secretsmanagerWrapper.CreateWithKMSKey("alias/myapp"),
+		secretsmanagerWrapper.CreateWithDescription("database credentials"),
+		secretsmanagerWrapper.CreateWithTags(map[string]string{"team": "payments", "env": "prod"}),
+	)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"DB_USER":"admin"}`, smMock.secret("new-secret"))
+	require.Equal(t, "alias/myapp", aws.ToString(smMock.createInput.KmsKeyId))
+	require.Equal(t, "database credentials", aws.ToString(smMock.createInput.Description))
+	require.Equal(t, []types.Tag{
+		{Key: aws.String("env"), Value: aws.String("prod")},
+		{Key: aws.String("team"), Value: aws.String("payments")},
+	}, smMock.createInput.Tags)
+
+	// Creating an existing secret fails.
+	var exists *types.ResourceExistsException
+	require.ErrorAs(t, secretsManager.CreateSecret(context.Background(), "new-secret", nil), &exists)
+}
+
+func TestSecretsManager_DeleteSecret(t *testing.T) {
+	smMock := &mockLifecycleClient{mockSecretsByIDClient: mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret": `{"DB_USER":"admin"}`,
+	}}}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	_, err := secretsManager.Get("DB_USER")
+	require.NoError(t, err)
+
+	require.NoError(t, secretsManager.DeleteSecret(context.Background(), "test-secret", true))
+	require.True(t, aws.ToBool(smMock.deleteInput.ForceDeleteWithoutRecovery))
+
+	// The cache is purged, so the deleted secret is no longer served.
+	_, err = secretsManager.GetWithContext(context.Background(), "DB_USER")
+	var notFound *types.ResourceNotFoundException
+	require.ErrorAs(t, err, &notFound)
+
+	require.NoError(t, secretsManager.DeleteSecret(context.Background(), "other-secret", false))
+	require.Nil(t, smMock.deleteInput.ForceDeleteWithoutRecovery)
+}
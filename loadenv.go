@@ -0,0 +1,106 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// loadEnvOptions holds the settings of LoadIntoEnv.
+type loadEnvOptions struct {
+	prefix       string
+	transform    func(key string) string
+	keepExisting bool
+}
+
+// LoadEnvOption defines a functional option for configuring LoadIntoEnv.
+type LoadEnvOption func(*loadEnvOptions)
+
+// LoadEnvPrefix prepends prefix to the name of every environment variable.
+func LoadEnvPrefix(prefix string) LoadEnvOption {
+	return func(o *loadEnvOptions) {
+		o.prefix = prefix
+	}
+}
+
+// LoadEnvTransform derives the names of the environment variables from the secret keys with
+// transform. The prefix set by LoadEnvPrefix is prepended afterwards.
+func LoadEnvTransform(transform func(key string) string) LoadEnvOption {
+	return func(o *loadEnvOptions) {
+		o.transform = transform
+	}
+}
+
+// LoadEnvUpperSnake names the environment variables in upper snake case, so that the keys
+// "dbPassword" and "db-password" are both set as DB_PASSWORD.
+func LoadEnvUpperSnake() LoadEnvOption {
+	return LoadEnvTransform(upperSnake)
+}
+
+// LoadEnvKeepExisting leaves environment variables that are already set untouched, so that values
+// set explicitly, e.g. by an operator, take precedence over the secret.
+func LoadEnvKeepExisting() LoadEnvOption {
+	return func(o *loadEnvOptions) {
+		o.keepExisting = true
+	}
+}
+
+// LoadIntoEnv sets an environment variable of the current process for every key of the secret,
+// named after the key unless set otherwise by opts. Existing variables are overwritten, unless
+// LoadEnvKeepExisting is set. Unlike ExecWithSecrets, the values are visible to the whole process
+// and to every child process it starts. All values are read before any variable is set.
+func (s *SecretsManager) LoadIntoEnv(ctx context.Context, opts ...LoadEnvOption) error {
+	var o loadEnvOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	values, err := s.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		name := key
+		if o.transform != nil {
+			name = o.transform(key)
+		}
+		name = o.prefix + name
+		if _, ok := os.LookupEnv(name); ok && o.keepExisting {
+			continue
+		}
+		if err := os.Setenv(name, values[key]); err != nil {
+			return fmt.Errorf("failed to set environment variable for key %q: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// upperSnake converts key to upper snake case. Word boundaries are changes from lower to upper
+// case, as in "dbPassword" or "HTTPServer", and any character other than a letter or digit.
+func upperSnake(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	underscore := true // Avoids leading and repeated underscores.
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if !underscore {
+				b.WriteByte('_')
+				underscore = true
+			}
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) && !underscore {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextLower {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+		underscore = false
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
@@ -0,0 +1,48 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_LoadIntoEnv(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"dbPassword":"password","api-token":"token","HTTPServer.port":"8080"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+	for _, name := range []string{"APP_DB_PASSWORD", "APP_API_TOKEN", "APP_HTTP_SERVER_PORT", "APP_API-TOKEN", "APP_HTTPSERVER.PORT", "dbPassword", "api-token", "HTTPServer.port"} {
+		t.Setenv(name, "")
+		require.NoError(t, os.Unsetenv(name))
+	}
+
+	// By default, the variables are named after the keys.
+	require.NoError(t, secretsManager.LoadIntoEnv(context.Background()))
+	require.Equal(t, "password", os.Getenv("dbPassword"))
+	require.Equal(t, "token", os.Getenv("api-token"))
+
+	// Existing variables are kept if requested.
+	t.Setenv("APP_DB_PASSWORD", "explicit")
+	require.NoError(t, secretsManager.LoadIntoEnv(context.Background(),
+		secretsmanagerWrapper.LoadEnvPrefix("APP_"),
+		secretsmanagerWrapper.LoadEnvUpperSnake(),
+		secretsmanagerWrapper.LoadEnvKeepExisting(),
+	))
+	require.Equal(t, "explicit", os.Getenv("APP_DB_PASSWORD"))
+	require.Equal(t, "token", os.Getenv("APP_API_TOKEN"))
+	require.Equal(t, "8080", os.Getenv("APP_HTTP_SERVER_PORT"))
+
+	// Otherwise, they are overwritten.
+	require.NoError(t, secretsManager.LoadIntoEnv(context.Background(),
+		secretsmanagerWrapper.LoadEnvPrefix("APP_"),
+		secretsmanagerWrapper.LoadEnvTransform(func(key string) string {
+			return strings.ReplaceAll(strings.ToUpper(key), "DBPASSWORD", "DB_PASSWORD")
+		}),
+	))
+	require.Equal(t, "password", os.Getenv("APP_DB_PASSWORD"))
+	require.Equal(t, "token", os.Getenv("APP_API-TOKEN"))
+}
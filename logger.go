@@ -0,0 +1,176 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/aws/smithy-go/logging"
+	"github.com/go-logr/logr"
+)
+
+// Logger receives the log messages of a SecretsManager. args are alternating keys and values,
+// as with slog. Messages never contain secret values.
+type Logger interface {
+	Debug(ctx context.Context, msg string, args ...any)
+	Info(ctx context.Context, msg string, args ...any)
+	Error(ctx context.Context, msg string, args ...any)
+}
+
+// WithLogger sets the logger of the SecretsManager. By default, nothing is logged.
+func WithLogger(logger Logger) Option {
+	return func(s *SecretsManager) {
+		s.logger = logger
+	}
+}
+
+// WithSlogLogger sets a *slog.Logger as the logger of the SecretsManager, see WithLogger.
+// Messages carry structured fields, such as the secret name, key and attempt number.
+func WithSlogLogger(logger *slog.Logger) Option {
+	return WithLogger(NewSlogLogger(logger))
+}
+
+// LogLevel is the minimum level of the messages a SecretsManager logs.
+type LogLevel int
+
+// Log levels, from most to least verbose.
+const (
+	// LogLevelDebug logs all messages, leaving filtering to the logger. This is the default.
+	LogLevelDebug LogLevel = iota
+	// LogLevelInfo logs Info and Error messages.
+	LogLevelInfo
+	// LogLevelError only logs errors.
+	LogLevelError
+	// LogLevelOff logs nothing.
+	LogLevelOff
+)
+
+// WithLogLevel restricts the messages the SecretsManager logs to level and above, independent of
+// the level of the logger itself, e.g. to keep high-traffic services to errors only.
+func WithLogLevel(level LogLevel) Option {
+	return func(s *SecretsManager) {
+		s.logLevel = level
+	}
+}
+
+// applyLogLevel wraps the logger to filter out messages below the log level.
+func (s *SecretsManager) applyLogLevel() {
+	switch {
+	case s.logger == nil || s.logLevel <= LogLevelDebug:
+	case s.logLevel >= LogLevelOff:
+		s.logger = nil
+	default:
+		s.logger = levelLogger{logger: s.logger, level: s.logLevel}
+	}
+}
+
+// levelLogger drops messages below level.
+type levelLogger struct {
+	logger Logger
+	level  LogLevel
+}
+
+func (l levelLogger) Debug(ctx context.Context, msg string, args ...any) {
+	if l.level <= LogLevelDebug {
+		l.logger.Debug(ctx, msg, args...)
+	}
+}
+
+func (l levelLogger) Info(ctx context.Context, msg string, args ...any) {
+	if l.level <= LogLevelInfo {
+		l.logger.Info(ctx, msg, args...)
+	}
+}
+
+func (l levelLogger) Error(ctx context.Context, msg string, args ...any) {
+	if l.level <= LogLevelError {
+		l.logger.Error(ctx, msg, args...)
+	}
+}
+
+// log returns the logger of the SecretsManager.
+func (s *SecretsManager) log() Logger {
+	if s.logger == nil {
+		return nopLogger{}
+	}
+	return s.logger
+}
+
+// nopLogger discards all messages.
+type nopLogger struct{}
+
+func (nopLogger) Debug(context.Context, string, ...any) {}
+func (nopLogger) Info(context.Context, string, ...any)  {}
+func (nopLogger) Error(context.Context, string, ...any) {}
+
+// slogLogger adapts a *slog.Logger.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger returns a Logger that writes to logger.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return slogLogger{logger: logger}
+}
+
+func (l slogLogger) Debug(ctx context.Context, msg string, args ...any) {
+	l.logger.DebugContext(ctx, msg, args...)
+}
+
+func (l slogLogger) Info(ctx context.Context, msg string, args ...any) {
+	l.logger.InfoContext(ctx, msg, args...)
+}
+
+func (l slogLogger) Error(ctx context.Context, msg string, args ...any) {
+	l.logger.ErrorContext(ctx, msg, args...)
+}
+
+// logrLogger adapts a logr.Logger, which also covers zap through zapr.
+type logrLogger struct {
+	logger logr.Logger
+}
+
+// NewLogrLogger returns a Logger that writes to logger. Debug messages are logged at V(1).
+// A zap logger can be used through github.com/go-logr/zapr.
+func NewLogrLogger(logger logr.Logger) Logger {
+	return logrLogger{logger: logger}
+}
+
+func (l logrLogger) Debug(_ context.Context, msg string, args ...any) {
+	l.logger.V(1).Info(msg, args...)
+}
+
+func (l logrLogger) Info(_ context.Context, msg string, args ...any) {
+	l.logger.Info(msg, args...)
+}
+
+func (l logrLogger) Error(_ context.Context, msg string, args ...any) {
+	l.logger.Error(nil, msg, args...)
+}
+
+// sdkLogger adapts the logger of a SecretsManager to the logger of the AWS SDK, so the SDK logging
+// enabled with WithClientLogMode goes where the wrapper's messages go. Warnings are logged at Info,
+// everything else at Debug.
+type sdkLogger struct {
+	s   *SecretsManager
+	ctx context.Context
+}
+
+// Logf implements logging.Logger.
+func (l sdkLogger) Logf(classification logging.Classification, format string, v ...any) {
+	ctx := l.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	msg := fmt.Sprintf(format, v...)
+	if classification == logging.Warn {
+		l.s.log().Info(ctx, msg, "secret", l.s.secretName, "source", "aws-sdk")
+		return
+	}
+	l.s.log().Debug(ctx, msg, "secret", l.s.secretName, "source", "aws-sdk")
+}
+
+// WithContext implements logging.ContextLogger.
+func (l sdkLogger) WithContext(ctx context.Context) logging.Logger {
+	return sdkLogger{s: l.s, ctx: ctx}
+}
@@ -0,0 +1,177 @@
+package secretsmanager_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer that is safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSecretsManager_WithLogger(t *testing.T) {
+	var buf syncBuffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})
+	loggers := map[string]secretsmanagerWrapper.Logger{
+		"slog": secretsmanagerWrapper.NewSlogLogger(slog.New(handler)),
+		"logr": secretsmanagerWrapper.NewLogrLogger(logr.FromSlogHandler(handler)),
+	}
+	for name, logger := range loggers {
+		t.Run(name, func(t *testing.T) {
+			smMock := &mockSecretsManagerClient{}
+			smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+			secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+				secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+				secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+				secretsmanagerWrapper.WithCacheTTL(time.Minute),
+				secretsmanagerWrapper.WithLogger(logger),
+			)
+			require.NoError(t, err)
+
+			_, err = secretsManager.GetWithContext(context.Background(), "DB_PASSWORD")
+			require.NoError(t, err)
+			out := buf.String()
+			require.Contains(t, out, "secret refreshed")
+			require.Contains(t, out, "cacheHit=false")
+			require.NotContains(t, out, "=password")
+		})
+	}
+}
+
+func TestSecretsManager_Watch_LogsScheduledForDeletion(t *testing.T) {
+	var buf syncBuffer
+	deletedDate := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(&mockDeletedSecretClient{deletedDate: &deletedDate}),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithLogger(secretsmanagerWrapper.NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	secretsManager.Watch(ctx, "DB_PASSWORD", time.Second, func(string) {})
+	require.Eventually(t, func() bool {
+		return bytes.Contains([]byte(buf.String()), []byte("scheduled for deletion"))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSecretsManager_WithLatencyBudget(t *testing.T) {
+	var buf syncBuffer
+	smMock := &mockSecretsManagerClient{delay: 20 * time.Millisecond}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithLogger(secretsmanagerWrapper.NewSlogLogger(slog.New(slog.NewTextHandler(&buf, nil)))),
+		secretsmanagerWrapper.WithLatencyBudget(secretsmanagerWrapper.OperationRefresh, time.Millisecond),
+		secretsmanagerWrapper.WithLatencyBudget(secretsmanagerWrapper.OperationGet, time.Hour),
+	)
+	require.NoError(t, err)
+
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "latency budget exceeded")
+	require.Contains(t, buf.String(), "operation=refresh")
+	require.NotContains(t, buf.String(), "operation=get")
+}
+
+func TestSecretsManager_WithSlogLogger(t *testing.T) {
+	var buf syncBuffer
+	smMock := &mockSecretsManagerClient{err: errors.New("simulated AWS error")}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithRetry(2, time.Millisecond, time.Millisecond),
+		secretsmanagerWrapper.WithSlogLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+	)
+	require.NoError(t, err)
+
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.Error(t, err)
+	require.Contains(t, buf.String(), `"msg":"fetch attempt failed","secret":"test-secret","attempt":1,"maxAttempts":2`)
+	require.Contains(t, buf.String(), `"attempt":2`)
+}
+
+func TestSecretsManager_WithLogLevel(t *testing.T) {
+	for _, tt := range []struct {
+		level     secretsmanagerWrapper.LogLevel
+		wantDebug bool
+		wantInfo  bool
+	}{
+		{level: secretsmanagerWrapper.LogLevelDebug, wantDebug: true, wantInfo: true},
+		{level: secretsmanagerWrapper.LogLevelInfo, wantInfo: true},
+		{level: secretsmanagerWrapper.LogLevelError},
+		{level: secretsmanagerWrapper.LogLevelOff},
+	} {
+		var buf syncBuffer
+		smMock := &mockSecretsManagerClient{}
+		smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+		secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+			secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+			secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+			secretsmanagerWrapper.WithLogLevel(tt.level),
+			// The logger itself logs everything.
+			secretsmanagerWrapper.WithSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		)
+		require.NoError(t, err)
+
+		_, err = secretsManager.Get("DB_PASSWORD")
+		require.NoError(t, err)
+		require.Equal(t, tt.wantDebug, strings.Contains(buf.String(), "secret lookup"), tt.level)
+		require.Equal(t, tt.wantInfo, strings.Contains(buf.String(), "secret refreshed"), tt.level)
+	}
+}
+
+// principalKey is the context key under which tests carry the authenticated user.
+type principalKey struct{}
+
+func TestSecretsManager_WithPrincipalExtractor(t *testing.T) {
+	var buf syncBuffer
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		secretsmanagerWrapper.WithPrincipalExtractor(func(ctx context.Context) secretsmanagerWrapper.Principal {
+			user, _ := ctx.Value(principalKey{}).(string)
+			return secretsmanagerWrapper.Principal{ID: user, Type: "user"}
+		}),
+	)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), principalKey{}, "alice")
+	_, err = secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "principal=alice principalType=user")
+
+	// Reads without a principal are logged without one.
+	_, err = secretsManager.GetWithContext(context.Background(), "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, 1, strings.Count(buf.String(), "principal="))
+}
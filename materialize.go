@@ -0,0 +1,118 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// MaterializedFile is a secret value written to a file, see MaterializeToFile.
+type MaterializedFile struct {
+	path    string
+	mode    os.FileMode
+	watcher *Watcher
+
+	// mu serializes rewrites with Close.
+	mu     sync.Mutex
+	closed bool
+}
+
+// MaterializeToFile writes the value for key to path with the given permissions, e.g. 0o600, for
+// software that can only read credentials from disk. The file is rewritten whenever the value
+// changes, which is checked every cache TTL, until ctx is done or Close is called.
+// Call Close to overwrite the file with zeros and remove it.
+func (s *SecretsManager) MaterializeToFile(ctx context.Context, key, path string, mode os.FileMode) (*MaterializedFile, error) {
+	val, err := s.GetWithContext(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	f := &MaterializedFile{path: path, mode: mode}
+	if err := f.write(val); err != nil {
+		return nil, err
+	}
+
+	f.watcher = s.Watch(ctx, key, s.cacheTTL, func(newVal string) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if !f.closed {
+			// A failed rewrite keeps the previous value, and is retried on the next change.
+			_ = f.write(newVal)
+		}
+	})
+	return f, nil
+}
+
+// Path returns the path of the file.
+func (f *MaterializedFile) Path() string {
+	return f.path
+}
+
+// write atomically replaces the file with val.
+func (f *MaterializedFile) write(val string) error {
+	return writeFileAtomic(f.path, f.mode, val)
+}
+
+// writeFileAtomic replaces the file at path with val, so readers never see a partial value.
+func writeFileAtomic(path string, mode os.FileMode, val string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(mode); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if _, err := tmp.WriteString(val); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Close stops rewriting the file, overwrites its contents with zeros and removes it.
+// Overwriting is best effort: on copy-on-write or journaling file systems, and on SSDs,
+// earlier copies of the data may survive.
+func (f *MaterializedFile) Close() error {
+	f.watcher.Stop()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	return removeShredded(f.path)
+}
+
+// removeShredded overwrites the contents of the file at path with zeros and removes it.
+func removeShredded(path string) error {
+	shredErr := shred(path)
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Join(shredErr, err)
+	}
+	return shredErr
+}
+
+// shred overwrites the contents of the file at path with zeros.
+func shred(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	info, err := file.Stat()
+	if err == nil {
+		_, err = file.Write(make([]byte, info.Size()))
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	return errors.Join(err, file.Close())
+}
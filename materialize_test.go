@@ -0,0 +1,50 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_MaterializeToFile(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"TLS_KEY":"initialKey"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(20*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "tls.key")
+	f, err := secretsManager.MaterializeToFile(context.Background(), "TLS_KEY", path, 0o600)
+	require.NoError(t, err)
+	require.Equal(t, path, f.Path())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "initialKey", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// The file is rewritten after a rotation.
+	smMock.secretValue.Store(`{"TLS_KEY":"rotatedKey"}`)
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(path)
+		return err == nil && string(data) == "rotatedKey"
+	}, time.Second, 10*time.Millisecond)
+
+	// Close removes the file, and it is not written again.
+	require.NoError(t, f.Close())
+	require.NoFileExists(t, path)
+	smMock.secretValue.Store(`{"TLS_KEY":"laterKey"}`)
+	time.Sleep(60 * time.Millisecond)
+	require.NoFileExists(t, path)
+	require.NoError(t, f.Close())
+}
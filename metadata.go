@@ -0,0 +1,80 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// ErrAccessDenied is returned for reads that the access hook set by WithAccessHook rejected.
+var ErrAccessDenied = errors.New("access denied")
+
+// metadataKey is the context key of the call metadata.
+type metadataKey struct{}
+
+// ContextWithMetadata returns a copy of ctx that carries md as call metadata, such as
+// {"purpose": "db-connect"}, merged over any metadata ctx already carries. Reads made with the
+// returned context pass it to the access hook, and record it in the read log message and span.
+func ContextWithMetadata(ctx context.Context, md map[string]string) context.Context {
+	merged := maps.Clone(MetadataFromContext(ctx))
+	if merged == nil {
+		merged = make(map[string]string, len(md))
+	}
+	maps.Copy(merged, md)
+	return context.WithValue(ctx, metadataKey{}, merged)
+}
+
+// MetadataFromContext returns the call metadata carried by ctx, or nil if there is none.
+// The map must not be modified.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	md, _ := ctx.Value(metadataKey{}).(map[string]string)
+	return md
+}
+
+// Access describes a read of a key, as passed to an AccessHook.
+type Access struct {
+	SecretName string
+	// Key is the key that is read, or empty for reads of the whole payload, such as GetInto and
+	// GetBinary.
+	Key string
+	// Principal is the principal of the read, as set by WithPrincipalExtractor.
+	Principal Principal
+	// Metadata is the call metadata, as set by ContextWithMetadata. It must not be modified.
+	Metadata map[string]string
+}
+
+// AccessHook is called before a key is read. Returning an error rejects the read.
+type AccessHook func(ctx context.Context, access Access) error
+
+// WithAccessHook calls hook before every read of a key, so that organizations can enforce their
+// own policies, e.g. based on the purpose passed in the call metadata, or keep audit trails. It
+// covers every method that returns values, including Iterate, Keys and GetAll, for which it is
+// called once per key, and the methods built on them, such as LoadIntoEnv, SyncToDir, WatchAll
+// and DBConnector. A read that the hook rejects fails with an error wrapping ErrAccessDenied and
+// the error of the hook; for a read of several keys, so does the whole read.
+func WithAccessHook(hook AccessHook) Option {
+	return func(s *SecretsManager) {
+		s.accessHook = hook
+	}
+}
+
+// checkAccess runs the access hook, if any, for a read of key.
+func (s *SecretsManager) checkAccess(ctx context.Context, key string) error {
+	if s.accessHook == nil {
+		return nil
+	}
+	err := s.accessHook(ctx, Access{
+		SecretName: s.secretName,
+		Key:        key,
+		Principal:  s.principal(ctx),
+		Metadata:   MetadataFromContext(ctx),
+	})
+	if err != nil {
+		if key == "" {
+			return fmt.Errorf("read %s: %w: %w", s.secretName, ErrAccessDenied, err)
+		}
+		return fmt.Errorf("read %s: %w: %w", key, ErrAccessDenied, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextWithMetadata(t *testing.T) {
+	ctx := context.Background()
+	require.Nil(t, secretsmanagerWrapper.MetadataFromContext(ctx))
+
+	ctx = secretsmanagerWrapper.ContextWithMetadata(ctx, map[string]string{"purpose": "db-connect", "team": "billing"})
+	child := secretsmanagerWrapper.ContextWithMetadata(ctx, map[string]string{"purpose": "migration"})
+	require.Equal(t, map[string]string{"purpose": "migration", "team": "billing"}, secretsmanagerWrapper.MetadataFromContext(child))
+	// The parent is unchanged.
+	require.Equal(t, "db-connect", secretsmanagerWrapper.MetadataFromContext(ctx)["purpose"])
+}
+
+func TestSecretsManager_WithAccessHook(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	var buf syncBuffer
+	var accesses []secretsmanagerWrapper.Access
+	errNoPurpose := errors.New("no purpose given")
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+		secretsmanagerWrapper.WithSlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))),
+		secretsmanagerWrapper.WithAccessHook(func(_ context.Context, access secretsmanagerWrapper.Access) error {
+			accesses = append(accesses, access)
+			if access.Metadata["purpose"] == "" {
+				return errNoPurpose
+			}
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+
+	// Reads without a purpose are rejected before AWS is called.
+	_, err = secretsManager.GetWithContext(context.Background(), "DB_PASSWORD")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrAccessDenied)
+	require.ErrorIs(t, err, errNoPurpose)
+	require.Zero(t, smMock.callCount)
+
+	ctx := secretsmanagerWrapper.ContextWithMetadata(context.Background(), map[string]string{"purpose": "db-connect"})
+	val, err := secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+	require.Len(t, accesses, 2)
+	require.Equal(t, secretsmanagerWrapper.Access{
+		SecretName: "test-secret",
+		Key:        "DB_PASSWORD",
+		Metadata:   map[string]string{"purpose": "db-connect"},
+	}, accesses[1])
+
+	// The metadata is recorded in the read log message.
+	require.Contains(t, buf.String(), "metadata=map[purpose:db-connect]")
+}
+
+func TestSecretsManager_WithAccessHook_AllReadPaths(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_USER":"admin","DB_PASSWORD":"password"}`)
+	// The hook rejects reads of DB_PASSWORD and of the whole payload.
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Hour),
+		secretsmanagerWrapper.WithBinarySecrets(false),
+		secretsmanagerWrapper.WithPrimaryRegion("us-test-2", time.Minute),
+		secretsmanagerWrapper.WithPrimarySecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithAccessHook(func(_ context.Context, access secretsmanagerWrapper.Access) error {
+			if access.Key == "DB_PASSWORD" || access.Key == "" {
+				return errors.New("restricted")
+			}
+			return nil
+		}),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	connector, err := secretsmanagerWrapper.NewDBConnector(secretsManager, "secretsmanager-test", "{{.DB_USER}}")
+	require.NoError(t, err)
+	reads := map[string]func() error{
+		"GetAll": func() error { _, err := secretsManager.GetAll(ctx); return err },
+		"Iterate": func() error {
+			return secretsManager.Iterate(ctx, func(string, secretsmanagerWrapper.LazyValue) bool { return true })
+		},
+		"Keys":      func() error { _, err := secretsManager.Keys(ctx); return err },
+		"GetInto":   func() error { var v map[string]string; return secretsManager.GetInto(ctx, &v) },
+		"GetBinary": func() error { _, err := secretsManager.GetBinary(ctx); return err },
+		"GetVersion": func() error {
+			_, err := secretsManager.GetVersion(ctx, "DB_PASSWORD", secretsmanagerWrapper.VersionStagePrevious)
+			return err
+		},
+		"GetFromPrimary": func() error { _, err := secretsManager.GetFromPrimary(ctx, "DB_PASSWORD"); return err },
+		"LoadIntoEnv": func() error {
+			return secretsManager.LoadIntoEnv(ctx, secretsmanagerWrapper.LoadEnvPrefix("ACCESS_TEST_"))
+		},
+		"SyncToDir":   func() error { _, err := secretsManager.SyncToDir(ctx, t.TempDir()); return err },
+		"DBConnector": func() error { _, err := connector.Connect(ctx); return err },
+		"WatchAll": func() error {
+			watcher := secretsManager.WatchAll(ctx, time.Hour, func(map[string]string) {})
+			defer watcher.Stop()
+			require.Eventually(t, func() bool { return watcher.LastError() != nil }, time.Second, time.Millisecond)
+			return watcher.LastError()
+		},
+	}
+	for name, read := range reads {
+		require.ErrorIs(t, read(), secretsmanagerWrapper.ErrAccessDenied, name)
+	}
+	_, ok := os.LookupEnv("ACCESS_TEST_DB_USER")
+	require.False(t, ok, "no variable is set if any read is rejected")
+
+	// Reads of the allowed keys only still succeed.
+	val, err := secretsManager.GetVersion(ctx, "DB_USER", secretsmanagerWrapper.VersionStagePrevious)
+	require.NoError(t, err)
+	require.Equal(t, "admin", val)
+	watcher := secretsManager.WatchKeys(ctx, []string{"DB_USER"}, time.Hour, func(map[string]string) {})
+	defer watcher.Stop()
+	require.Eventually(t, func() bool { return !watcher.LastCheckedAt().IsZero() }, time.Second, time.Millisecond)
+	require.NoError(t, watcher.LastError())
+}
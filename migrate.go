@@ -0,0 +1,66 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// MigrationReport describes what MigrateFromEnv did with each environment variable.
+type MigrationReport struct {
+	// Written lists the variables that were written to the secret, under the same key.
+	Written []string
+	// Unchanged lists the variables whose value the secret already held under the same key.
+	Unchanged []string
+	// Missing lists the variables that were not set, and were skipped.
+	Missing []string
+}
+
+// MigrateFromEnv copies the environment variables named by keys into the secret, each under a key
+// of the same name, to move a service configured through the environment to Secrets Manager. The
+// values are written as one new version with PutAll, and then read back from AWS and compared, so
+// an error is returned if the secret does not hold them afterwards; the report is then returned
+// too, as the write went through. Variables that are not set are skipped, and reported as
+// missing. It is meant to be run once, e.g. from a migration command.
+func (s *SecretsManager) MigrateFromEnv(ctx context.Context, keys []string) (*MigrationReport, error) {
+	current, err := s.fetchSecret(ctx, &secretsmanager.GetSecretValueInput{SecretId: &s.secretName})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MigrationReport{}
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		val, ok := os.LookupEnv(key)
+		if !ok {
+			report.Missing = append(report.Missing, key)
+			continue
+		}
+		if cur, found := current.values[key]; found && cur == val {
+			report.Unchanged = append(report.Unchanged, key)
+			continue
+		}
+		values[key] = val
+		report.Written = append(report.Written, key)
+	}
+	if len(values) == 0 {
+		return report, nil
+	}
+	if err := s.PutAll(ctx, values); err != nil {
+		return nil, err
+	}
+
+	// Verify the round trip against AWS rather than the cache.
+	written, err := s.fetchSecret(ctx, &secretsmanager.GetSecretValueInput{SecretId: &s.secretName})
+	if err != nil {
+		return report, fmt.Errorf("migrate %s: failed to read back the secret: %w", s.secretName, err)
+	}
+	for key, val := range values {
+		if got, ok := written.values[key]; !ok || got != val {
+			return report, fmt.Errorf("migrate %s: key %q does not hold the value of the environment variable after the write", s.secretName, key)
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,77 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_MigrateFromEnv(t *testing.T) {
+	smMock := &mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret": `{"DB_USER":"admin"}`,
+	}}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+	t.Setenv("DB_USER", "admin")
+	t.Setenv("DB_PASSWORD", "password")
+
+	report, err := secretsManager.MigrateFromEnv(context.Background(), []string{"DB_USER", "DB_PASSWORD", "DB_HOST"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"DB_PASSWORD"}, report.Written)
+	require.Equal(t, []string{"DB_USER"}, report.Unchanged)
+	require.Equal(t, []string{"DB_HOST"}, report.Missing)
+	require.JSONEq(t, `{"DB_USER":"admin","DB_PASSWORD":"password"}`, smMock.secret("test-secret"))
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+
+	// Running it again writes nothing.
+	report, err = secretsManager.MigrateFromEnv(context.Background(), []string{"DB_USER", "DB_PASSWORD"})
+	require.NoError(t, err)
+	require.Empty(t, report.Written)
+	require.Equal(t, []string{"DB_USER", "DB_PASSWORD"}, report.Unchanged)
+}
+
+// mockFailingReadBackClient simulates a Secrets Manager client whose reads fail after a write.
+type mockFailingReadBackClient struct {
+	*mockSecretsByIDClient
+	written bool
+}
+
+func (m *mockFailingReadBackClient) GetSecretValue(ctx context.Context, input *awsSecretsManager.GetSecretValueInput, opts ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
+	if m.written {
+		return nil, errors.New("connection reset")
+	}
+	return m.mockSecretsByIDClient.GetSecretValue(ctx, input, opts...)
+}
+
+func (m *mockFailingReadBackClient) PutSecretValue(ctx context.Context, input *awsSecretsManager.PutSecretValueInput, opts ...func(*awsSecretsManager.Options)) (*awsSecretsManager.PutSecretValueOutput, error) {
+	out, err := m.mockSecretsByIDClient.PutSecretValue(ctx, input, opts...)
+	m.written = err == nil
+	return out, err
+}
+
+func TestSecretsManager_MigrateFromEnv_ReadBackFails(t *testing.T) {
+	smMock := &mockFailingReadBackClient{mockSecretsByIDClient: &mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret": `{"DB_USER":"admin"}`,
+	}}}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithRetry(1, time.Millisecond, time.Millisecond),
+	)
+	require.NoError(t, err)
+	t.Setenv("DB_PASSWORD", "password")
+
+	// The write went through, so the report is returned along with the error.
+	report, err := secretsManager.MigrateFromEnv(context.Background(), []string{"DB_PASSWORD"})
+	require.ErrorContains(t, err, "failed to read back the secret")
+	require.NotNil(t, report)
+	require.Equal(t, []string{"DB_PASSWORD"}, report.Written)
+	require.JSONEq(t, `{"DB_USER":"admin","DB_PASSWORD":"password"}`, smMock.secret("test-secret"))
+}
@@ -0,0 +1,76 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// MultiSecretsManager reads several secrets through one set of AWS clients.
+// Each secret is cached and refreshed on its own, under its name.
+type MultiSecretsManager struct {
+	managers map[string]*SecretsManager
+}
+
+// NewMultiSecretsManager creates a MultiSecretsManager for the given secrets.
+// The options apply to every secret, and the AWS clients are created once and shared. Clients that
+// are rebuilt, by ResetIdentity or after expired credentials, are rebuilt for all secrets.
+func NewMultiSecretsManager(region string, secretNames []string, kmsKeyID string, opts ...Option) (*MultiSecretsManager, error) {
+	if len(secretNames) == 0 {
+		return nil, fmt.Errorf("no secret names given")
+	}
+
+	m := &MultiSecretsManager{managers: make(map[string]*SecretsManager, len(secretNames))}
+	for _, name := range secretNames {
+		if _, ok := m.managers[name]; ok {
+			return nil, fmt.Errorf("secret %q is given more than once", name)
+		}
+		secretsManager := newSecretsManager(region, name, kmsKeyID, opts)
+		if first := m.first(); first != nil {
+			// Share the clients of the first secret rather than loading the AWS config again.
+			secretsManager.clients = first.clients
+		}
+		if err := secretsManager.init(context.Background()); err != nil {
+			return nil, fmt.Errorf("secret %q: %w", name, err)
+		}
+		m.managers[name] = secretsManager
+	}
+	return m, nil
+}
+
+// first returns an arbitrary manager, or nil if there is none yet.
+func (m *MultiSecretsManager) first() *SecretsManager {
+	for _, secretsManager := range m.managers {
+		return secretsManager
+	}
+	return nil
+}
+
+// Get retrieves the value of key in the secret with the given name.
+func (m *MultiSecretsManager) Get(ctx context.Context, secretName, key string) (string, error) {
+	secretsManager, err := m.Secret(secretName)
+	if err != nil {
+		return "", err
+	}
+	return secretsManager.GetWithContext(ctx, key)
+}
+
+// Secret returns the SecretsManager of the secret with the given name,
+// giving access to everything a SecretsManager offers, such as Watch.
+func (m *MultiSecretsManager) Secret(secretName string) (*SecretsManager, error) {
+	secretsManager, ok := m.managers[secretName]
+	if !ok {
+		return nil, fmt.Errorf("secret %q is not managed by this MultiSecretsManager", secretName)
+	}
+	return secretsManager, nil
+}
+
+// SecretNames returns the names of the managed secrets in sorted order.
+func (m *MultiSecretsManager) SecretNames() []string {
+	names := make([]string, 0, len(m.managers))
+	for name := range m.managers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
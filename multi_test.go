@@ -0,0 +1,96 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiSecretsManager(t *testing.T) {
+	smMock := &mockSecretsByIDClient{secrets: map[string]string{
+		"db":  `{"PASSWORD":"dbPassword"}`,
+		"api": `{"PASSWORD":"apiPassword"}`,
+	}}
+	multi, err := secretsmanagerWrapper.NewMultiSecretsManager("us-test-1", []string{"db", "api"}, "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"api", "db"}, multi.SecretNames())
+
+	// The same key is read from each secret separately.
+	val, err := multi.Get(context.Background(), "db", "PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "dbPassword", val)
+	val, err = multi.Get(context.Background(), "api", "PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "apiPassword", val)
+
+	// Each secret is cached.
+	_, err = multi.Get(context.Background(), "db", "PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+
+	_, err = multi.Get(context.Background(), "unknown", "PASSWORD")
+	require.ErrorContains(t, err, "not managed")
+
+	_, err = secretsmanagerWrapper.NewMultiSecretsManager("us-test-1", []string{"db", "db"}, "test-kms-key")
+	require.ErrorContains(t, err, "more than once")
+}
+
+// expiringHTTPClient answers the request with the given index with an ExpiredTokenException and
+// every other request with a GetSecretValue response.
+type expiringHTTPClient struct {
+	requests int
+	expireAt int
+}
+
+func (c *expiringHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests++
+	status, body := http.StatusOK, `{"Name":"test-secret","SecretString":"{\"DB_PASSWORD\":\"password\"}","VersionId":"v1"}`
+	if c.requests == c.expireAt {
+		status, body = http.StatusBadRequest, `{"__type":"ExpiredTokenException","message":"The security token included in the request is expired"}`
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.1"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestMultiSecretsManager_SharesDefaultClients(t *testing.T) {
+	var credentialCalls atomic.Int32
+	cfg := aws.Config{
+		Region: "eu-test-1",
+		// The fetch of the second secret fails with expired credentials.
+		HTTPClient: &expiringHTTPClient{expireAt: 2},
+		Credentials: aws.NewCredentialsCache(aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			credentialCalls.Add(1)
+			return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, nil
+		})),
+	}
+	multi, err := secretsmanagerWrapper.NewMultiSecretsManager("", []string{"db", "api"}, "",
+		secretsmanagerWrapper.WithAWSConfig(cfg),
+		secretsmanagerWrapper.WithoutCacheEncryption(),
+	)
+	require.NoError(t, err)
+
+	_, err = multi.Get(context.Background(), "db", "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), credentialCalls.Load())
+
+	// The second secret shares the default clients of the first rather than having them injected,
+	// so it recovers by retrieving new credentials.
+	val, err := multi.Get(context.Background(), "api", "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+	require.Equal(t, int32(2), credentialCalls.Load())
+}
@@ -0,0 +1,122 @@
+// Package paramstore reads parameters from AWS Systems Manager Parameter Store as secrets, so
+// that the cache, watchers and cache encryption of the secretsmanager package work for them:
+//
+//	sm, err := secretsmanager.NewSecretsManager(region, "/myapp/prod/", kmsKeyID,
+//		secretsmanager.WithSecretStore(paramstore.NewStore(ssm.NewFromConfig(cfg))))
+package paramstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/janduursma/aws-secretsmanager-wrapper-go"
+)
+
+// Client defines the subset of methods needed from the AWS Systems Manager client to read
+// parameters from Parameter Store.
+type Client interface {
+	GetParameter(ctx context.Context, input *ssm.GetParameterInput, opts ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+	GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput, opts ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+}
+
+var (
+	// The default SSM client implements Client.
+	_ Client = (*ssm.Client)(nil)
+	// Store is a secretsmanager.SecretStore.
+	_ secretsmanager.SecretStore = (*Store)(nil)
+)
+
+// Store reads secrets from Parameter Store. It implements secretsmanager.SecretStore.
+type Store struct {
+	client Client
+}
+
+// NewStore returns a Store that reads parameters with client, such as the default SSM client.
+func NewStore(client Client) *Store {
+	return &Store{client: client}
+}
+
+// ReadSecret implements secretsmanager.SecretStore. The SecretID names a parameter or, if it
+// ends with a slash, a path. A parameter is returned as the secret string, so a parameter holding
+// a JSON object is read like a JSON secret. A path is returned as a JSON object of all parameters
+// below it, keyed by their names relative to the path, e.g. "db/password" for
+// "/myapp/prod/db/password". SecureString parameters are decrypted. The VersionId of a parameter
+// is its version; that of a path changes whenever any parameter below it changes. A VersionID in
+// the request selects a version of a parameter. Version stages are not supported.
+func (s *Store) ReadSecret(ctx context.Context, req secretsmanager.SecretRequest) (*secretsmanager.StoredSecret, error) {
+	name := req.SecretID
+	if req.VersionStage != "" && req.VersionStage != secretsmanager.VersionStageCurrent {
+		return nil, fmt.Errorf("parameter %s: version stage %s is not supported", name, req.VersionStage)
+	}
+	if strings.HasSuffix(name, "/") {
+		if req.VersionID != "" {
+			return nil, fmt.Errorf("path %s: versions are not supported", name)
+		}
+		return s.getPath(ctx, name)
+	}
+	return s.getParameter(ctx, name, req.VersionID)
+}
+
+// getParameter reads the parameter with the given name, at version if it is not empty.
+func (s *Store) getParameter(ctx context.Context, name, version string) (*secretsmanager.StoredSecret, error) {
+	selector := name
+	if version != "" {
+		selector += ":" + version
+	}
+	out, err := s.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           &selector,
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &secretsmanager.StoredSecret{
+		SecretString: aws.ToString(out.Parameter.Value),
+		VersionID:    strconv.FormatInt(out.Parameter.Version, 10),
+	}, nil
+}
+
+// getPath reads all parameters below path as a JSON object.
+func (s *Store) getPath(ctx context.Context, path string) (*secretsmanager.StoredSecret, error) {
+	var params []types.Parameter
+	paginator := ssm.NewGetParametersByPathPaginator(s.client, &ssm.GetParametersByPathInput{
+		Path:           &path,
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		params = append(params, page.Parameters...)
+	}
+	sort.Slice(params, func(i, j int) bool {
+		return aws.ToString(params[i].Name) < aws.ToString(params[j].Name)
+	})
+
+	values := make(map[string]string, len(params))
+	h := sha256.New()
+	for _, p := range params {
+		name := aws.ToString(p.Name)
+		values[strings.TrimPrefix(name, path)] = aws.ToString(p.Value)
+		_, _ = fmt.Fprintf(h, "%d:%s%d;", len(name), name, p.Version)
+	}
+	payload, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	return &secretsmanager.StoredSecret{
+		SecretString: string(payload),
+		VersionID:    hex.EncodeToString(h.Sum(nil))[:32],
+	}, nil
+}
@@ -0,0 +1,152 @@
+package paramstore_test
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/janduursma/aws-secretsmanager-wrapper-go/paramstore"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSMClient serves parameters from memory, one parameter per page of GetParametersByPath.
+type fakeSSMClient struct {
+	mu       sync.Mutex
+	params   map[string]string
+	versions map[string]int64
+	// names records the names requested with GetParameter.
+	names []string
+}
+
+func (c *fakeSSMClient) put(name, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.params[name] = value
+	c.versions[name]++
+}
+
+func (c *fakeSSMClient) GetParameter(_ context.Context, input *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.names = append(c.names, aws.ToString(input.Name))
+	name, _, _ := strings.Cut(aws.ToString(input.Name), ":")
+	value, ok := c.params[name]
+	if !ok {
+		return nil, &types.ParameterNotFound{}
+	}
+	return &ssm.GetParameterOutput{Parameter: &types.Parameter{Name: &name, Value: &value, Version: c.versions[name]}}, nil
+}
+
+func (c *fakeSSMClient) GetParametersByPath(_ context.Context, input *ssm.GetParametersByPathInput, _ ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var names []string
+	for name := range c.params {
+		if strings.HasPrefix(name, aws.ToString(input.Path)) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	i, _ := strconv.Atoi(aws.ToString(input.NextToken))
+	if i >= len(names) {
+		return &ssm.GetParametersByPathOutput{}, nil
+	}
+	name := names[i]
+	out := &ssm.GetParametersByPathOutput{Parameters: []types.Parameter{
+		{Name: aws.String(name), Value: aws.String(c.params[name]), Version: c.versions[name]},
+	}}
+	if i+1 < len(names) {
+		out.NextToken = aws.String(strconv.Itoa(i + 1))
+	}
+	return out, nil
+}
+
+func newFakeSSMClient(params map[string]string) *fakeSSMClient {
+	c := &fakeSSMClient{params: map[string]string{}, versions: map[string]int64{}}
+	for name, value := range params {
+		c.put(name, value)
+	}
+	return c
+}
+
+func TestStore_Path(t *testing.T) {
+	client := newFakeSSMClient(map[string]string{
+		"/myapp/prod/DB_USER":        "admin",
+		"/myapp/prod/DB_PASSWORD":    "password",
+		"/myapp/prod/tls/cert":       "cert",
+		"/myapp/staging/DB_PASSWORD": "stagingPassword",
+	})
+	sm, err := secretsmanagerWrapper.NewBuilder().
+		Region("us-test-1").
+		Secret("/myapp/prod/").
+		Options(
+			secretsmanagerWrapper.WithSecretStore(paramstore.NewStore(client)),
+			secretsmanagerWrapper.WithoutCacheEncryption(),
+			secretsmanagerWrapper.WithCacheTTL(time.Hour),
+		).
+		Build(context.Background())
+	require.NoError(t, err)
+
+	values, err := sm.GetAll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"DB_USER": "admin", "DB_PASSWORD": "password", "tls/cert": "cert"}, values)
+	version := sm.Version()
+	require.NotEmpty(t, version)
+
+	// The version of the path changes with any parameter below it.
+	client.put("/myapp/prod/DB_PASSWORD", "newPassword")
+	require.NoError(t, sm.Refresh(context.Background()))
+	val, err := sm.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "newPassword", val)
+	require.NotEqual(t, version, sm.Version())
+}
+
+func TestStore_Parameter(t *testing.T) {
+	client := newFakeSSMClient(map[string]string{
+		"/myapp/prod/db": `{"DB_USER":"admin","DB_PASSWORD":"password"}`,
+	})
+	store := paramstore.NewStore(client)
+	sm, err := secretsmanagerWrapper.NewBuilder().
+		Region("us-test-1").
+		Secret("/myapp/prod/db").
+		Options(
+			secretsmanagerWrapper.WithSecretStore(store),
+			secretsmanagerWrapper.WithoutCacheEncryption(),
+		).
+		Build(context.Background())
+	require.NoError(t, err)
+
+	val, err := sm.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+	require.Equal(t, "1", sm.Version())
+
+	// A VersionId selects a version of the parameter.
+	_, err = store.ReadSecret(context.Background(), secretsmanagerWrapper.SecretRequest{
+		SecretID:  "/myapp/prod/db",
+		VersionID: "1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "/myapp/prod/db:1", client.names[len(client.names)-1])
+
+	_, err = store.ReadSecret(context.Background(), secretsmanagerWrapper.SecretRequest{
+		SecretID:     "/myapp/prod/db",
+		VersionStage: secretsmanagerWrapper.VersionStagePrevious,
+	})
+	require.ErrorContains(t, err, "not supported")
+
+	_, err = store.ReadSecret(context.Background(), secretsmanagerWrapper.SecretRequest{
+		SecretID: "/myapp/prod/missing",
+	})
+	var notFound *types.ParameterNotFound
+	require.ErrorAs(t, err, &notFound)
+}
@@ -0,0 +1,75 @@
+package secretsmanager
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// AWS partitions.
+const (
+	PartitionAWS      = "aws"
+	PartitionAWSChina = "aws-cn"
+	PartitionAWSGov   = "aws-us-gov"
+	PartitionAWSISO   = "aws-iso"
+	PartitionAWSISOB  = "aws-iso-b"
+	PartitionAWSISOE  = "aws-iso-e"
+	PartitionAWSISOF  = "aws-iso-f"
+	PartitionAWSEUSC  = "aws-eusc"
+)
+
+// regionPartitions maps region prefixes to their partition. Longer prefixes come first.
+var regionPartitions = []struct{ prefix, partition string }{
+	{"us-gov-", PartitionAWSGov},
+	{"us-isob-", PartitionAWSISOB},
+	{"us-isof-", PartitionAWSISOF},
+	{"us-iso-", PartitionAWSISO},
+	{"eu-isoe-", PartitionAWSISOE},
+	{"eusc-", PartitionAWSEUSC},
+	{"cn-", PartitionAWSChina},
+	{"af-", PartitionAWS},
+	{"ap-", PartitionAWS},
+	{"ca-", PartitionAWS},
+	{"eu-", PartitionAWS},
+	{"il-", PartitionAWS},
+	{"me-", PartitionAWS},
+	{"mx-", PartitionAWS},
+	{"sa-", PartitionAWS},
+	{"us-", PartitionAWS},
+}
+
+// PartitionForRegion returns the AWS partition of region, e.g. "aws-cn" for "cn-north-1"
+// and "aws-us-gov" for "us-gov-west-1". It returns "" for regions it does not know.
+func PartitionForRegion(region string) string {
+	for _, p := range regionPartitions {
+		if strings.HasPrefix(region, p.prefix) {
+			return p.partition
+		}
+	}
+	return ""
+}
+
+// resolveARNRegion checks the secret name and KMS key ID, if they are ARNs, against the region.
+// If no region is set, the region of the ARNs is used, so that a secret given by its ARN is read
+// from its own region and partition, e.g. GovCloud, without further configuration. The partition
+// of an ARN is only checked against a region whose partition is known.
+func (s *SecretsManager) resolveARNRegion() error {
+	for _, id := range []string{s.secretName, s.kmsKeyID} {
+		if !arn.IsARN(id) {
+			continue
+		}
+		parsed, err := arn.Parse(id)
+		if err != nil {
+			return fmt.Errorf("invalid ARN %s: %w", id, err)
+		}
+		if s.region == "" {
+			s.region = parsed.Region
+		}
+		if partition := PartitionForRegion(s.region); partition != "" && parsed.Partition != partition {
+			return fmt.Errorf("ARN %s is in partition %s, but region %s is in partition %s",
+				id, parsed.Partition, s.region, partition)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package secretsmanager_test
+
+import (
+	"testing"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartitionForRegion(t *testing.T) {
+	require.Equal(t, secretsmanagerWrapper.PartitionAWS, secretsmanagerWrapper.PartitionForRegion("eu-west-1"))
+	require.Equal(t, secretsmanagerWrapper.PartitionAWSChina, secretsmanagerWrapper.PartitionForRegion("cn-northwest-1"))
+	require.Equal(t, secretsmanagerWrapper.PartitionAWSGov, secretsmanagerWrapper.PartitionForRegion("us-gov-west-1"))
+	require.Equal(t, secretsmanagerWrapper.PartitionAWSISO, secretsmanagerWrapper.PartitionForRegion("us-iso-east-1"))
+	require.Equal(t, secretsmanagerWrapper.PartitionAWSISOB, secretsmanagerWrapper.PartitionForRegion("us-isob-east-1"))
+	require.Equal(t, secretsmanagerWrapper.PartitionAWSISOE, secretsmanagerWrapper.PartitionForRegion("eu-isoe-west-1"))
+	require.Equal(t, secretsmanagerWrapper.PartitionAWSEUSC, secretsmanagerWrapper.PartitionForRegion("eusc-de-east-1"))
+	require.Empty(t, secretsmanagerWrapper.PartitionForRegion("xx-unknown-1"))
+}
+
+func TestNewSecretsManager_ARNPartitions(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	newManager := func(region, secretName, kmsKeyID string) error {
+		_, err := secretsmanagerWrapper.NewSecretsManager(region, secretName, kmsKeyID,
+			secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+			secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		)
+		return err
+	}
+
+	// ARNs in the GovCloud and China partitions are accepted, also without a region.
+	require.NoError(t, newManager("us-gov-west-1", "arn:aws-us-gov:secretsmanager:us-gov-west-1:111122223333:secret:db-AbCdEf", "test-kms-key"))
+	require.NoError(t, newManager("", "arn:aws-cn:secretsmanager:cn-north-1:111122223333:secret:db-AbCdEf",
+		"arn:aws-cn:kms:cn-north-1:111122223333:key/1234abcd"))
+	require.NoError(t, newManager("us-east-1", "arn:aws:secretsmanager:us-east-1:111122223333:secret:db-AbCdEf", "test-kms-key"))
+
+	// So are ARNs in the isolated partitions, and in regions whose partition is not known.
+	require.NoError(t, newManager("", "arn:aws-iso:secretsmanager:us-iso-east-1:111122223333:secret:db-AbCdEf", "test-kms-key"))
+	require.NoError(t, newManager("us-isob-east-1", "arn:aws-iso-b:secretsmanager:us-isob-east-1:111122223333:secret:db-AbCdEf", "test-kms-key"))
+	require.NoError(t, newManager("", "arn:aws-new:secretsmanager:xx-new-1:111122223333:secret:db-AbCdEf", "test-kms-key"))
+
+	// An ARN in another partition than the region is rejected.
+	err := newManager("us-east-1", "arn:aws-us-gov:secretsmanager:us-gov-west-1:111122223333:secret:db-AbCdEf", "test-kms-key")
+	require.ErrorContains(t, err, "partition aws-us-gov")
+	err = newManager("cn-north-1", "test-secret", "arn:aws:kms:us-east-1:111122223333:key/1234abcd")
+	require.ErrorContains(t, err, "partition aws-cn")
+}
@@ -0,0 +1,33 @@
+package secretsmanager
+
+import (
+	"context"
+)
+
+// Principal identifies the authenticated user or service on whose behalf a secret is read.
+type Principal struct {
+	// ID identifies the principal, e.g. a user name or service account.
+	ID string
+	// Type describes the kind of principal, e.g. "user" or "service". It is optional.
+	Type string
+}
+
+// PrincipalExtractor returns the principal carried in the context of a request, or the zero
+// Principal if there is none.
+type PrincipalExtractor func(ctx context.Context) Principal
+
+// WithPrincipalExtractor attributes reads to the principal that extract returns for their context.
+// The principal is added to the read log message and, as enduser.id, to the read span.
+func WithPrincipalExtractor(extract PrincipalExtractor) Option {
+	return func(s *SecretsManager) {
+		s.principalExtractor = extract
+	}
+}
+
+// principal returns the principal of ctx, or the zero Principal if no extractor is set.
+func (s *SecretsManager) principal(ctx context.Context) Principal {
+	if s.principalExtractor == nil {
+		return Principal{}
+	}
+	return s.principalExtractor(ctx)
+}
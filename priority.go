@@ -0,0 +1,99 @@
+package secretsmanager
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Priority orders the refreshes of SecretsManagers while AWS is throttling fetches.
+type Priority int
+
+const (
+	// PriorityLow is for secrets that are only needed by batch jobs and other background work.
+	PriorityLow Priority = iota - 1
+	// PriorityNormal is the default.
+	PriorityNormal
+	// PriorityHigh is for secrets that are needed to serve traffic, such as credentials.
+	PriorityHigh
+)
+
+// WithPriority sets the priority of the refreshes of the secret. Once a fetch of any secret in
+// the process is throttled, fetches are serialized until the backoff is over, and waiting
+// refreshes of higher priority go first. Outside of throttling, the priority has no effect.
+func WithPriority(p Priority) Option {
+	return func(s *SecretsManager) {
+		s.priority = min(max(p, PriorityLow), PriorityHigh)
+	}
+}
+
+// fetchScheduler is the process-wide fetch slot that is handed out by priority while throttled.
+var fetchScheduler = &priorityScheduler{}
+
+// priorityScheduler hands out a single slot, to the waiter with the highest priority first.
+type priorityScheduler struct {
+	mu             sync.Mutex
+	throttledUntil time.Time
+	busy           bool
+	// waiting holds the waiters per priority, from low to high, in order of arrival.
+	waiting [PriorityHigh - PriorityLow + 1][]chan struct{}
+}
+
+// throttled marks fetches as throttled until t.
+func (ps *priorityScheduler) throttled(t time.Time) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	if t.After(ps.throttledUntil) {
+		ps.throttledUntil = t
+	}
+}
+
+// acquire waits for the slot if fetches are throttled or the slot is taken, and returns a
+// function that releases it.
+func (ps *priorityScheduler) acquire(ctx context.Context, p Priority) (func(), error) {
+	ps.mu.Lock()
+	if !ps.busy {
+		if time.Now().After(ps.throttledUntil) {
+			ps.mu.Unlock()
+			return func() {}, nil
+		}
+		ps.busy = true
+		ps.mu.Unlock()
+		return ps.release, nil
+	}
+	ready := make(chan struct{})
+	i := p - PriorityLow
+	ps.waiting[i] = append(ps.waiting[i], ready)
+	ps.mu.Unlock()
+
+	select {
+	case <-ready:
+		return ps.release, nil
+	case <-ctx.Done():
+		ps.mu.Lock()
+		if j := slices.Index(ps.waiting[i], ready); j >= 0 {
+			ps.waiting[i] = slices.Delete(ps.waiting[i], j, j+1)
+			ps.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		ps.mu.Unlock()
+		// The slot was handed over in the meantime, so pass it on.
+		ps.release()
+		return nil, ctx.Err()
+	}
+}
+
+// release hands the slot to the waiter with the highest priority, or frees it.
+func (ps *priorityScheduler) release() {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	for i := len(ps.waiting) - 1; i >= 0; i-- {
+		if len(ps.waiting[i]) > 0 {
+			close(ps.waiting[i][0])
+			ps.waiting[i] = ps.waiting[i][1:]
+			return
+		}
+	}
+	ps.busy = false
+}
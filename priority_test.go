@@ -0,0 +1,67 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/smithy-go"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// namedSecretsManagerClient reports its name on calls before delegating to the mock.
+type namedSecretsManagerClient struct {
+	*mockSecretsManagerClient
+	name  string
+	calls chan<- string
+}
+
+func (c *namedSecretsManagerClient) GetSecretValue(ctx context.Context, input *awsSecretsManager.GetSecretValueInput, optFns ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
+	c.calls <- c.name
+	return c.mockSecretsManagerClient.GetSecretValue(ctx, input, optFns...)
+}
+
+func TestSecretsManager_WithPriority(t *testing.T) {
+	calls := make(chan string, 4)
+	newManager := func(name string, sm *mockSecretsManagerClient, priority secretsmanagerWrapper.Priority) *secretsmanagerWrapper.SecretsManager {
+		sm.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+		secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", name, "test-kms-key",
+			secretsmanagerWrapper.WithSecretsManagerClient(&namedSecretsManagerClient{sm, name, calls}),
+			secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+			secretsmanagerWrapper.WithRetry(1, time.Millisecond, time.Second),
+			secretsmanagerWrapper.WithPriority(priority),
+		)
+		require.NoError(t, err)
+		return secretsManager
+	}
+	throttled := newManager("throttled", &mockSecretsManagerClient{err: &smithy.GenericAPIError{Code: "ThrottlingException"}}, secretsmanagerWrapper.PriorityNormal)
+	slow := newManager("slow", &mockSecretsManagerClient{delay: 200 * time.Millisecond}, secretsmanagerWrapper.PriorityNormal)
+	low := newManager("low", &mockSecretsManagerClient{}, secretsmanagerWrapper.PriorityLow)
+	high := newManager("high", &mockSecretsManagerClient{}, secretsmanagerWrapper.PriorityHigh)
+
+	// A throttled fetch makes the following fetches wait for each other.
+	_, err := throttled.Get("DB_PASSWORD")
+	require.Error(t, err)
+	require.Equal(t, "throttled", <-calls)
+
+	done := make(chan struct{}, 3)
+	get := func(secretsManager *secretsmanagerWrapper.SecretsManager) {
+		_, err := secretsManager.Get("DB_PASSWORD")
+		require.NoError(t, err)
+		done <- struct{}{}
+	}
+	go get(slow)
+	require.Equal(t, "slow", <-calls)
+	go get(low)
+	time.Sleep(20 * time.Millisecond)
+	go get(high)
+
+	// The refresh of high priority goes first, even though it started last.
+	require.Equal(t, "high", <-calls)
+	require.Equal(t, "low", <-calls)
+	for range 3 {
+		<-done
+	}
+}
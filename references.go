@@ -0,0 +1,83 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// referencePrefix marks a value that references a key of another secret,
+// e.g. "@secretsmanager:shared/db#password".
+const referencePrefix = "@secretsmanager:"
+
+// ErrReferenceCycle is returned when secret references refer back to themselves.
+var ErrReferenceCycle = errors.New("secret reference cycle")
+
+// WithSecretReferences enables resolving values of the form "@secretsmanager:<secret>#<key>"
+// to the value of key in the referenced secret, which may be a name or an ARN. References may
+// be nested; cycles are reported as ErrReferenceCycle. Resolved values are cached like any
+// other value, and each referenced secret is fetched at most once per refresh.
+func WithSecretReferences() Option {
+	return func(s *SecretsManager) {
+		s.resolveRefs = true
+	}
+}
+
+// referenceResolver resolves the references of one refresh.
+type referenceResolver struct {
+	s *SecretsManager
+	// secrets holds the secrets fetched during this refresh, by name.
+	secrets map[string]map[string]string
+}
+
+// resolveReferences returns values with all references replaced by the values they point to.
+func (s *SecretsManager) resolveReferences(ctx context.Context, values map[string]string) (map[string]string, error) {
+	r := &referenceResolver{
+		s:       s,
+		secrets: map[string]map[string]string{s.secretName: values},
+	}
+	resolved := make(map[string]string, len(values))
+	for k, v := range values {
+		rv, err := r.resolve(ctx, v, []string{s.secretName + "#" + k})
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", k, err)
+		}
+		resolved[k] = rv
+	}
+	return resolved, nil
+}
+
+// resolve follows value if it is a reference. chain holds the references followed so far.
+func (r *referenceResolver) resolve(ctx context.Context, value string, chain []string) (string, error) {
+	if !strings.HasPrefix(value, referencePrefix) {
+		return value, nil
+	}
+	ref := strings.TrimPrefix(value, referencePrefix)
+	i := strings.LastIndex(ref, "#")
+	if i <= 0 || i == len(ref)-1 {
+		return "", fmt.Errorf("invalid secret reference %q", value)
+	}
+	name, key := ref[:i], ref[i+1:]
+	if slices.Contains(chain, ref) {
+		return "", fmt.Errorf("%w: %s", ErrReferenceCycle, strings.Join(append(chain, ref), " -> "))
+	}
+
+	values, ok := r.secrets[name]
+	if !ok {
+		secret, err := r.s.fetchSecret(ctx, &secretsmanager.GetSecretValueInput{SecretId: &name})
+		if err != nil {
+			return "", err
+		}
+		values = secret.values
+		r.secrets[name] = values
+	}
+	v, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", ref, ErrSecretNotFound)
+	}
+	return r.resolve(ctx, v, append(chain, ref))
+}
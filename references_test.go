@@ -0,0 +1,72 @@
+package secretsmanager_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// newReferencingSecretsManagerForTest creates a SecretsManager for "test-secret" with secret references enabled.
+func newReferencingSecretsManagerForTest(t *testing.T, sm secretsmanagerWrapper.Client) *secretsmanagerWrapper.SecretsManager {
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(sm),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithRetry(1, time.Millisecond, time.Millisecond),
+		secretsmanagerWrapper.WithSecretReferences(),
+	)
+	require.NoError(t, err)
+	return secretsManager
+}
+
+func TestSecretsManager_Get_SecretReferences(t *testing.T) {
+	smMock := &mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret":    `{"DB_PASSWORD":"@secretsmanager:shared/db#password","DB_HOST":"@secretsmanager:shared/db#host","API_KEY":"plain"}`,
+		"shared/db":      `{"password":"sharedPassword","host":"@secretsmanager:shared/network#db_host"}`,
+		"shared/network": `{"db_host":"db.internal"}`,
+	}}
+	secretsManager := newReferencingSecretsManagerForTest(t, smMock)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "sharedPassword", val)
+
+	// Nested references are followed.
+	val, err = secretsManager.Get("DB_HOST")
+	require.NoError(t, err)
+	require.Equal(t, "db.internal", val)
+
+	val, err = secretsManager.Get("API_KEY")
+	require.NoError(t, err)
+	require.Equal(t, "plain", val)
+
+	// Each secret was fetched once.
+	require.Equal(t, int32(3), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_Get_SecretReferenceErrors(t *testing.T) {
+	// A cycle across secrets is detected.
+	smMock := &mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret": `{"A":"@secretsmanager:other#B"}`,
+		"other":       `{"B":"@secretsmanager:test-secret#A"}`,
+	}}
+	_, err := newReferencingSecretsManagerForTest(t, smMock).Get("A")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrReferenceCycle)
+
+	// A reference to a missing key is reported.
+	smMock = &mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret": `{"A":"@secretsmanager:other#missing"}`,
+		"other":       `{"B":"value"}`,
+	}}
+	_, err = newReferencingSecretsManagerForTest(t, smMock).Get("A")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+
+	// So is a malformed reference.
+	smMock = &mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret": `{"A":"@secretsmanager:other"}`,
+	}}
+	_, err = newReferencingSecretsManagerForTest(t, smMock).Get("A")
+	require.ErrorContains(t, err, "invalid secret reference")
+}
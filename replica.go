@@ -0,0 +1,116 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// WithPrimaryRegion marks the secret as a replica of a secret in primaryRegion. On every refresh,
+// the version read from the replica is compared to the current version in the primary region. If
+// the replica lags behind for longer than maxReplicaLag since the primary changed, the secret is
+// read from the primary region instead. This requires a primary client that implements
+// SecretDescriber, such as the default client. If the primary region cannot be reached, the
+// replica is trusted, so an outage of the primary region does not affect reads.
+func WithPrimaryRegion(primaryRegion string, maxReplicaLag time.Duration) Option {
+	return func(s *SecretsManager) {
+		s.primaryRegion = primaryRegion
+		s.maxReplicaLag = maxReplicaLag
+	}
+}
+
+// WithPrimarySecretsManagerClient allows overriding the default Secrets Manager client for the
+// primary region for testing purposes. It requires WithPrimaryRegion.
+func WithPrimarySecretsManagerClient(client Client) Option {
+	return func(s *SecretsManager) {
+		s.clients.primary = client
+	}
+}
+
+// primaryAPI returns the current Secrets Manager client for the primary region.
+func (s *SecretsManager) primaryAPI() Client {
+	s.clients.mu.RLock()
+	defer s.clients.mu.RUnlock()
+	return s.clients.primary
+}
+
+// primarySecretID returns the ID of the secret in the primary region. ARNs carry their region,
+// so they are rewritten to the primary region.
+func (s *SecretsManager) primarySecretID() string {
+	parsed, err := arn.Parse(s.secretName)
+	if err != nil {
+		return s.secretName
+	}
+	parsed.Region = s.primaryRegion
+	return parsed.String()
+}
+
+// checkReplicaLag returns secret, as read from the replica, unless the replica lags behind the
+// primary region for too long, in which case the secret is read from the primary region.
+// Pinned versions are never checked.
+func (s *SecretsManager) checkReplicaLag(ctx context.Context, secret *fetchedSecret) (*fetchedSecret, error) {
+	if s.primaryRegion == "" || s.pinnedVersionID != "" || (s.versionStage != "" && s.versionStage != VersionStageCurrent) {
+		return secret, nil
+	}
+	describer, ok := s.primaryAPI().(SecretDescriber)
+	if !ok {
+		return secret, nil
+	}
+	primaryID := s.primarySecretID()
+	out, err := describer.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: &primaryID})
+	if err != nil {
+		s.log().Error(ctx, "failed to describe secret in primary region; trusting replica",
+			"secret", s.secretName, "primaryRegion", s.primaryRegion, "error", err)
+		return secret, nil
+	}
+
+	var current string
+	for versionID, stages := range out.VersionIdsToStages {
+		if slices.Contains(stages, VersionStageCurrent) {
+			current = versionID
+		}
+	}
+	if current == "" || current == secret.versionID || out.LastChangedDate == nil ||
+		time.Since(*out.LastChangedDate) <= s.maxReplicaLag {
+		return secret, nil
+	}
+
+	s.log().Info(ctx, "replica lags behind primary region; reading from primary",
+		"secret", s.secretName, "primaryRegion", s.primaryRegion,
+		"replicaVersion", secret.versionID, "primaryVersion", current)
+	return s.fetchSecretFrom(ctx, s.primaryAPI, &secretsmanager.GetSecretValueInput{SecretId: &primaryID})
+}
+
+// GetFromPrimary retrieves the value for the given key from the primary region, for critical keys
+// that must never be read from a lagging replica. It requires WithPrimaryRegion. The value is read
+// from AWS on every call and not cached.
+func (s *SecretsManager) GetFromPrimary(ctx context.Context, key string) (string, error) {
+	if s.primaryRegion == "" {
+		return "", errors.New("no primary region set")
+	}
+	if err := s.checkAccess(ctx, key); err != nil {
+		return "", err
+	}
+	primaryID := s.primarySecretID()
+	secret, err := s.fetchSecretFrom(ctx, s.primaryAPI, &secretsmanager.GetSecretValueInput{SecretId: &primaryID})
+	if err != nil {
+		return "", err
+	}
+	values := secret.values
+	if s.resolveRefs {
+		values, err = s.resolveReferences(ctx, values)
+		if err != nil {
+			return "", err
+		}
+	}
+	val, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", key, ErrSecretNotFound)
+	}
+	return val, nil
+}
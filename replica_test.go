@@ -0,0 +1,102 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mockPrimarySecretsManagerClient simulates a Secrets Manager client in the primary region of a replicated secret.
+type mockPrimarySecretsManagerClient struct {
+	mockSecretsManagerClient
+	// lastChanged is reported by DescribeSecret as LastChangedDate.
+	lastChanged time.Time
+	// describeErr can simulate DescribeSecret errors.
+	describeErr error
+	// describeCount counts the DescribeSecret calls.
+	describeCount int32
+}
+
+// DescribeSecret simulates the AWS SDK DescribeSecret method, reporting the version of the current value as AWSCURRENT.
+func (m *mockPrimarySecretsManagerClient) DescribeSecret(_ context.Context, input *awsSecretsManager.DescribeSecretInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.DescribeSecretOutput, error) {
+	atomic.AddInt32(&m.describeCount, 1)
+	if m.describeErr != nil {
+		return nil, m.describeErr
+	}
+	return &awsSecretsManager.DescribeSecretOutput{
+		Name:            input.SecretId,
+		LastChangedDate: aws.Time(m.lastChanged),
+		VersionIdsToStages: map[string][]string{
+			versionOf(m.secretValue.Load().(string)): {secretsmanagerWrapper.VersionStageCurrent},
+		},
+	}, nil
+}
+
+func newReplicatedSecretsManagerForTest(t *testing.T, secretName string, replica, primary secretsmanagerWrapper.Client) *secretsmanagerWrapper.SecretsManager {
+	t.Helper()
+	sm, err := secretsmanagerWrapper.NewSecretsManager("eu-west-1", secretName, "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(replica),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithPrimaryRegion("us-east-1", time.Minute),
+		secretsmanagerWrapper.WithPrimarySecretsManagerClient(primary),
+		secretsmanagerWrapper.WithRetry(1, time.Millisecond, time.Millisecond),
+	)
+	require.NoError(t, err)
+	return sm
+}
+
+func TestSecretsManager_WithPrimaryRegion(t *testing.T) {
+	replica := &mockSecretsManagerClient{}
+	replica.secretValue.Store(`{"DB_PASSWORD":"old"}`)
+	primary := &mockPrimarySecretsManagerClient{lastChanged: time.Now()}
+	primary.secretValue.Store(`{"DB_PASSWORD":"new"}`)
+
+	// The replica lags behind, but within the allowed lag, so it is trusted.
+	sm := newReplicatedSecretsManagerForTest(t, "arn:aws:secretsmanager:eu-west-1:123456789012:secret:test-secret", replica, primary)
+	val, err := sm.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "old", val)
+	require.Equal(t, int32(0), primary.callCount)
+
+	// The replica lags behind for too long, so the secret is read from the primary region.
+	primary.lastChanged = time.Now().Add(-time.Hour)
+	sm = newReplicatedSecretsManagerForTest(t, "arn:aws:secretsmanager:eu-west-1:123456789012:secret:test-secret", replica, primary)
+	val, err = sm.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "new", val)
+	require.Equal(t, "arn:aws:secretsmanager:us-east-1:123456789012:secret:test-secret", primary.requestedSecretID.Load())
+
+	// An unreachable primary region does not affect reads from the replica.
+	primary.describeErr = errors.New("primary region unavailable")
+	sm = newReplicatedSecretsManagerForTest(t, "test-secret", replica, primary)
+	val, err = sm.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "old", val)
+}
+
+func TestSecretsManager_GetFromPrimary(t *testing.T) {
+	replica := &mockSecretsManagerClient{}
+	replica.secretValue.Store(`{"DB_PASSWORD":"old"}`)
+	primary := &mockPrimarySecretsManagerClient{lastChanged: time.Now()}
+	primary.secretValue.Store(`{"DB_PASSWORD":"new"}`)
+	sm := newReplicatedSecretsManagerForTest(t, "test-secret", replica, primary)
+
+	val, err := sm.GetFromPrimary(context.Background(), "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "new", val)
+	require.Equal(t, "test-secret", primary.requestedSecretID.Load())
+
+	_, err = sm.GetFromPrimary(context.Background(), "MISSING")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+
+	sm = newSecretsManagerForTest(t, replica, &mockKMSClient{}, time.Minute)
+	_, err = sm.GetFromPrimary(context.Background(), "DB_PASSWORD")
+	require.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MissingKeysError reports the keys that MustHaveKeys did not find in the secret.
+type MissingKeysError struct {
+	SecretID string
+	// Keys are the missing keys, sorted.
+	Keys []string
+}
+
+// Error implements error.
+func (e *MissingKeysError) Error() string {
+	return fmt.Sprintf("secret %s is missing keys: %s", e.SecretID, strings.Join(e.Keys, ", "))
+}
+
+// Is reports whether target is ErrSecretNotFound.
+func (e *MissingKeysError) Is(target error) bool {
+	return target == ErrSecretNotFound
+}
+
+// GetOrDefault retrieves the value for the given key, or fallback if the key is missing from the
+// secret and has no default set with WithDefaults. While the cache is fresh, a missing key does not
+// fetch the secret again. Other errors, such as a failed fetch, are returned as is.
+func (s *SecretsManager) GetOrDefault(key, fallback string) (string, error) {
+	val, err := s.GetWithContext(context.Background(), key)
+	if errors.Is(err, ErrSecretNotFound) {
+		return fallback, nil
+	}
+	return val, err
+}
+
+// MustHaveKeys fetches the secret and checks that it contains every key in keys, so a service can
+// fail at startup instead of at the first use of a missing key. If any are missing, it returns a
+// *MissingKeysError listing all of them. Defaults set with WithDefaults do not count as present.
+func (s *SecretsManager) MustHaveKeys(ctx context.Context, keys []string) error {
+	return s.checkKeys(ctx, keys)
+}
@@ -0,0 +1,56 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_GetOrDefault(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"LOG_LEVEL":"debug"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	val, err := secretsManager.GetOrDefault("LOG_LEVEL", "info")
+	require.NoError(t, err)
+	require.Equal(t, "debug", val)
+
+	val, err = secretsManager.GetOrDefault("MISSING", "info")
+	require.NoError(t, err)
+	require.Equal(t, "info", val)
+
+	// The fallback is served from the fresh cache, without fetching the secret again.
+	for range 5 {
+		_, err = secretsManager.GetOrDefault("MISSING", "info")
+		require.NoError(t, err)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_GetOrDefault_FetchError(t *testing.T) {
+	smMock := &mockSecretsManagerClient{err: errors.New("simulated SM error")}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	_, err := secretsManager.GetOrDefault("LOG_LEVEL", "info")
+	require.ErrorContains(t, err, "simulated SM error")
+}
+
+func TestSecretsManager_MustHaveKeys(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_USER":"user","DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	require.NoError(t, secretsManager.MustHaveKeys(context.Background(), []string{"DB_USER", "DB_PASSWORD"}))
+
+	err := secretsManager.MustHaveKeys(context.Background(), []string{"DB_USER", "API_SECRET", "API_KEY"})
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+	var missingErr *secretsmanagerWrapper.MissingKeysError
+	require.ErrorAs(t, err, &missingErr)
+	require.Equal(t, []string{"API_KEY", "API_SECRET"}, missingErr.Keys)
+	require.EqualError(t, err, "secret test-secret is missing keys: API_KEY, API_SECRET")
+}
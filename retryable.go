@@ -0,0 +1,48 @@
+package secretsmanager
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// throttlingCodes are the AWS error codes of throttling.
+var throttlingCodes = []string{
+	"ThrottlingException",
+	"Throttling",
+	"TooManyRequestsException",
+	"RequestLimitExceeded",
+}
+
+// retryableCodes are the AWS error codes of throttling and transient failures.
+var retryableCodes = append(slices.Clip(throttlingCodes),
+	"RequestTimeout",
+	"RequestTimeoutException",
+	"InternalServiceError",
+	"InternalFailure",
+	"ServiceUnavailable",
+)
+
+// isThrottling reports whether err signals that AWS throttled the operation.
+func isThrottling(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && slices.Contains(throttlingCodes, apiErr.ErrorCode())
+}
+
+// isRetryable reports whether retrying the operation that failed with err may succeed.
+// AWS API errors are only retried if they signal throttling, a timeout or a server fault, so
+// permanent errors such as ResourceNotFoundException or AccessDeniedException surface at once.
+// Other errors, e.g. network errors, are retried.
+func isRetryable(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	if apiErr.ErrorFault() == smithy.FaultServer || slices.Contains(retryableCodes, apiErr.ErrorCode()) {
+		return true
+	}
+	var respErr *smithyhttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() >= 500
+}
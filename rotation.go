@@ -0,0 +1,48 @@
+package secretsmanager
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// WithRotationAwareTTL derives the cache TTL from the rotation schedule of the secret instead of
+// using a fixed TTL: cached values are refreshed delay after the next scheduled rotation. Each
+// refresh reads the schedule with DescribeSecret, which requires a client that implements
+// SecretDescriber, such as the default client. The cache TTL still applies to secrets without
+// automatic rotation, if the schedule cannot be read, or if the rotation is overdue. Changes made
+// outside the rotation schedule are only seen after the next rotation.
+func WithRotationAwareTTL(delay time.Duration) Option {
+	return func(s *SecretsManager) {
+		s.rotationAwareTTL = true
+		s.rotationDelay = delay
+	}
+}
+
+// rotationRefreshAt returns when values fetched now should be refreshed according to the rotation
+// schedule, or the zero time if the cache TTL applies.
+func (s *SecretsManager) rotationRefreshAt(ctx context.Context) time.Time {
+	if !s.rotationAwareTTL {
+		return time.Time{}
+	}
+	describer, ok := s.secretsManagerAPI().(SecretDescriber)
+	if !ok {
+		return time.Time{}
+	}
+	out, err := describer.DescribeSecret(ctx, &secretsmanager.DescribeSecretInput{SecretId: &s.secretName})
+	if err != nil {
+		s.log().Error(ctx, "failed to read rotation schedule; using cache TTL", "secret", s.secretName, "error", err)
+		return time.Time{}
+	}
+	if !aws.ToBool(out.RotationEnabled) || out.NextRotationDate == nil {
+		return time.Time{}
+	}
+	until := time.Until(*out.NextRotationDate) + s.rotationDelay
+	if until <= 0 {
+		return time.Time{}
+	}
+	// Add to time.Now, so the result carries a monotonic clock reading.
+	return time.Now().Add(until)
+}
@@ -0,0 +1,59 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRotatingSecretsManagerClient simulates a Secrets Manager client for a secret with a rotation schedule.
+type mockRotatingSecretsManagerClient struct {
+	mockSecretsManagerClient
+	// nextRotation is reported by DescribeSecret; rotation is disabled if it is zero.
+	nextRotation time.Time
+}
+
+// DescribeSecret simulates the AWS SDK DescribeSecret method.
+func (m *mockRotatingSecretsManagerClient) DescribeSecret(_ context.Context, input *awsSecretsManager.DescribeSecretInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.DescribeSecretOutput, error) {
+	out := &awsSecretsManager.DescribeSecretOutput{Name: input.SecretId, RotationEnabled: aws.Bool(!m.nextRotation.IsZero())}
+	if !m.nextRotation.IsZero() {
+		out.NextRotationDate = aws.Time(m.nextRotation)
+	}
+	return out, nil
+}
+
+func TestSecretsManager_WithRotationAwareTTL(t *testing.T) {
+	for name, tc := range map[string]struct {
+		nextRotation time.Time
+		wantFetches  int32
+	}{
+		"rotation scheduled":   {nextRotation: time.Now().Add(time.Hour), wantFetches: 1},
+		"rotation overdue":     {nextRotation: time.Now().Add(-time.Hour), wantFetches: 2},
+		"rotation not enabled": {wantFetches: 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			smMock := &mockRotatingSecretsManagerClient{nextRotation: tc.nextRotation}
+			smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+			secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+				secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+				secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+				secretsmanagerWrapper.WithCacheTTL(time.Millisecond),
+				secretsmanagerWrapper.WithRotationAwareTTL(time.Minute),
+			)
+			require.NoError(t, err)
+
+			_, err = secretsManager.Get("DB_PASSWORD")
+			require.NoError(t, err)
+			time.Sleep(5 * time.Millisecond)
+			_, err = secretsManager.Get("DB_PASSWORD")
+			require.NoError(t, err)
+			require.Equal(t, tc.wantFetches, atomic.LoadInt32(&smMock.callCount))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package secretsmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxShapeLen is the maximum length in runes of a payload shape in an error.
+const maxShapeLen = 80
+
+// sanitizePayload strips artifacts that editing a secret in the console can leave around its JSON
+// payload, such as a UTF-8 byte order mark, zero-width spaces or trailing control characters.
+// It only does so if data is not valid JSON as is, and reports whether anything was stripped.
+func sanitizePayload(data []byte) ([]byte, bool) {
+	if json.Valid(data) {
+		return data, false
+	}
+	clean := bytes.TrimFunc(data, isPayloadArtifact)
+	return clean, len(clean) != len(data)
+}
+
+// isPayloadArtifact reports whether r is whitespace, a control character, a byte order mark or a
+// zero-width space, none of which may surround a JSON payload except plain whitespace.
+func isPayloadArtifact(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsControl(r) || r == '\uFEFF' || r == '\u200B'
+}
+
+// payloadShape describes the structure of a JSON payload for parse errors, without its keys or
+// values: strings become "", other literals 0, and whitespace is dropped. Control characters,
+// byte order marks and invalid UTF-8 are kept, as they are the likely cause of the error.
+// For example, `{"user": "admin", "port": 5432}` is described as `{"":"","":0}`.
+func payloadShape(data []byte) string {
+	var b strings.Builder
+	runes := 0
+	inString, escaped, inLiteral := false, false, false
+	for len(data) > 0 && runes < maxShapeLen {
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == '"':
+				inString = false
+				b.WriteString(`""`)
+				runes += 2
+			}
+			continue
+		}
+		literal := false
+		switch {
+		case r == '"':
+			inString = true
+		case strings.ContainsRune("{}[]:,", r):
+			b.WriteRune(r)
+			runes++
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+		case r == utf8.RuneError || isPayloadArtifact(r):
+			b.WriteRune(r)
+			runes++
+		default:
+			literal = true
+			if !inLiteral {
+				b.WriteByte('0')
+				runes++
+			}
+		}
+		inLiteral = literal
+	}
+	if inString {
+		b.WriteString(`"`)
+	}
+	if len(data) > 0 {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_SanitizesPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{name: "byte order mark", payload: "\ufeff{\"DB_PASSWORD\":\"password\"}"},
+		{name: "trailing control characters", payload: "{\"DB_PASSWORD\":\"password\"}\r\n\x00\x1a"},
+		{name: "zero-width space", payload: "\u200b{\"DB_PASSWORD\":\"password\"}\u200b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			smMock := &mockSecretsManagerClient{}
+			smMock.secretValue.Store(tt.payload)
+			secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+			val, err := secretsManager.Get("DB_PASSWORD")
+			require.NoError(t, err)
+			require.Equal(t, "password", val)
+
+			// The whole payload is sanitized as well.
+			var config struct {
+				DBPassword string `json:"DB_PASSWORD"`
+			}
+			require.NoError(t, secretsManager.GetInto(context.Background(), &config))
+			require.Equal(t, "password", config.DBPassword)
+		})
+	}
+}
+
+func TestSecretsManager_ParseErrorShowsPayloadShape(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store("{\"DB_USER\": \"admin\", \"DB_PASSWORD\": \"s3cret\", \"PORT\": 5432\x00, \"TLS\": true}")
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	_, err := secretsManager.Get("DB_PASSWORD")
+	require.ErrorContains(t, err, `payload shape "{\"\":\"\",\"\":\"\",\"\":0\x00,\"\":0}"`)
+	require.NotContains(t, err.Error(), "s3cret")
+	require.NotContains(t, err.Error(), "DB_PASSWORD")
+}
@@ -5,33 +5,44 @@
 package secretsmanager
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/janduursma/aws-secretsmanager-wrapper-go/awsapi"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 )
 
 // defaultCacheTTL is the default time-to-live for cached secrets.
 const defaultCacheTTL = 10 * time.Minute
 
+// defaultRefreshTimeout is the default time limit of a refresh, see WithRefreshTimeout.
+const defaultRefreshTimeout = 30 * time.Second
+
+// ErrSecretNotFound is returned when the requested key is not present in the secret.
+var ErrSecretNotFound = errors.New("secret not found")
+
 // Define interfaces for the AWS clients to inject mocks.
 
 // Client defines the subset of methods needed from the AWS Secrets Manager client.
-type Client interface {
-	GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput, opts ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
-}
+type Client = awsapi.Client
 
 // KMSClient defines the subset of methods needed from the AWS KMS client.
-type KMSClient interface {
-	Encrypt(ctx context.Context, input *kms.EncryptInput, opts ...func(*kms.Options)) (*kms.EncryptOutput, error)
-	Decrypt(ctx context.Context, input *kms.DecryptInput, opts ...func(*kms.Options)) (*kms.DecryptOutput, error)
-}
+type KMSClient = awsapi.KMSClient
 
 // SecretsManager is a wrapper around AWS Secrets Manager.
 type SecretsManager struct {
@@ -39,28 +50,190 @@ type SecretsManager struct {
 	secretName string
 	kmsKeyID   string
 
-	ctx context.Context
+	// clients are the AWS clients, which a MultiSecretsManager shares between its secrets.
+	clients *clientSet
+
+	// primaryRegion is the primary region of a replicated secret, read through the primary client
+	// when the replica lags more than maxReplicaLag behind.
+	primaryRegion string
+	maxReplicaLag time.Duration
+
+	// cipher encrypts cached values; nil selects KMS.
+	cipher CacheCipher
+	// logger receives log messages at logLevel and above; nil discards them.
+	logger   Logger
+	logLevel LogLevel
+
+	// principalExtractor attributes reads to the principal in their context.
+	principalExtractor PrincipalExtractor
+
+	// budgets holds the latency budgets of WithLatencyBudget.
+	budgets map[Operation]time.Duration
+
+	// tracer creates spans if WithTracerProvider is set.
+	tracer trace.Tracer
+
+	// decryptKeyIDs are the candidate KMS keys for decrypting cached values.
+	decryptKeyIDs []string
+	// encryptionContext is passed to every KMS operation on cached values.
+	encryptionContext map[string]string
 
-	secretsManagerClient Client
-	kmsClient            KMSClient
+	// clientLogMode enables SDK request/response logging on the default AWS clients.
+	clientLogMode aws.ClientLogMode
+	// awsConfig, if set, is used instead of the default AWS config.
+	awsConfig *aws.Config
+	// assumeRoleARN and assumeRoleExternalID set the role the default AWS clients assume.
+	assumeRoleARN        string
+	assumeRoleExternalID string
 
 	// Retry settings.
 	maxAttempts  int
 	initialDelay time.Duration
 	maxDelay     time.Duration
+	// priority orders the refreshes while fetches are throttled.
+	priority Priority
+	// isLeader, if set, reports whether this instance runs the periodic background polls.
+	isLeader func() bool
+	// profilerLabels attaches pprof labels to fetches and cache encryption.
+	profilerLabels bool
+	// accessHook, if set, is called before every read of a key.
+	accessHook AccessHook
+	// accessDeniedRecovery is the number of consecutive AccessDenied failures after which the
+	// credentials are refreshed, or 0 to never refresh them.
+	accessDeniedRecovery int
+	accessDeniedCount    atomic.Int32
+	// fetched records that the secret was fetched successfully at least once.
+	fetched atomic.Bool
 
 	// Local cache: maps individual keys to their encrypted values and fetch time.
-	cache     map[string]cachedSecret
+	cache map[string]cachedSecret
+	// keySet records when the keys in the cache were fetched; while it is fresh, keys that are not
+	// cached are missing from the secret, and are not fetched again.
+	keySet    cachedSecret
 	cacheTTL  time.Duration
 	cacheLock sync.RWMutex
+	// tombstones hold the values dropped by Invalidate for tombstoneGrace, see WithTombstones.
+	// They are guarded by cacheLock.
+	tombstones     map[string]tombstone
+	tombstoneGrace time.Duration
+	// cacheHits and cacheMisses count the reads of keys, see CacheStats.
+	cacheHits   atomic.Uint64
+	cacheMisses atomic.Uint64
+
+	// versionID is the VersionId of the cached secret.
+	versionID string
+	// contentHash is the hash of the cached payload, see ContentHash.
+	contentHash string
+
+	// softTTL is the age after which a cached entry is still served, but refreshed
+	// asynchronously. cacheTTL acts as the hard TTL.
+	softTTL    time.Duration
+	refreshing atomic.Bool
+
+	// cacheTTLJitter is the fraction of the cache TTL by which it is randomized per refresh.
+	cacheTTLJitter float64
+
+	// optionErrs are the errors of invalid option values, returned on creation.
+	optionErrs []error
+
+	// startupJitter is the maximum random delay before the first fetch.
+	startupJitter     time.Duration
+	startupJitterOnce sync.Once
+
+	// rotationAwareTTL derives the TTL from the rotation schedule, refreshing rotationDelay
+	// after the next scheduled rotation.
+	rotationAwareTTL bool
+	rotationDelay    time.Duration
+
+	// coldStartWindow is the period after startedAt with cold-start protection.
+	coldStartWindow time.Duration
+	startedAt       time.Time
+
+	// refreshGroup collapses concurrent refreshes into a single AWS fetch.
+	refreshGroup singleflight.Group
+	// versionCheckGroup shares the version checks of followers, last made at versionCheckedAt,
+	// among all watchers and the auto-refresher.
+	versionCheckGroup singleflight.Group
+	versionCheckMu    sync.Mutex
+	versionCheckedAt  time.Time
+	// refreshTimeout limits a shared refresh, which is not canceled with its callers.
+	refreshTimeout time.Duration
+	// attemptErr is the error of the last failed fetch attempt, reported to callers that stop
+	// waiting for a refresh.
+	attemptErr atomic.Pointer[error]
+
+	// watchPokes holds a channel per running watcher, to make it poll right away.
+	watchPokesLock sync.Mutex
+	watchPokes     map[chan struct{}]struct{}
+
+	// expiryMode controls whether cacheTTL counts from the fetch or the last read.
+	expiryMode ExpiryMode
+
+	// environment replaces "{env}" in secretName; expectedEnvironment guards against mismatches.
+	environment         string
+	expectedEnvironment string
+
+	// binarySecrets enables reading SecretBinary payloads, which are base64-decoded if base64Binary
+	// is set. binary caches the raw payload of a binary secret.
+	binarySecrets bool
+	base64Binary  bool
+	binary        cachedSecret
+
+	// pinnedVersionID and versionStage select the secret version to read instead of AWSCURRENT.
+	pinnedVersionID string
+	versionStage    string
+
+	// canaryCheck verifies new secret versions before they are cached.
+	canaryCheck CanaryCheck
+
+	// strictJSON enables the checks of WithStrictJSON.
+	strictJSON bool
+
+	// payload caches the raw payload of a string secret, see GetInto.
+	payload cachedSecret
+	// defaults are the values of keys that are missing from the secret.
+	defaults map[string]string
+	// structTag is the struct tag GetInto matches keys with; empty selects json tags.
+	structTag string
+
+	// resolveRefs enables resolving references to other secrets.
+	resolveRefs bool
+
+	// watchActions and watchRules hold the declarative watch configuration.
+	watchActions map[string]WatchAction
+	watchRules   []watchRule
+
+	// deadlineFallback is the grace window past cacheTTL during which a stale
+	// value may be served when a refresh runs out of context deadline.
+	deadlineFallback time.Duration
 }
 
-// cachedSecret holds an encrypted value and the time it was fetched.
+// cachedSecret holds an encrypted value, the time it was fetched and the time it was last read.
+// Both times must come straight from time.Now, so they carry a monotonic clock reading: TTL checks
+// through time.Since then stay correct when the wall clock jumps, e.g. after an NTP step or a VM
+// live migration. Do not round, serialize or convert them with In/UTC/Local, as that strips it.
 type cachedSecret struct {
 	encryptedValue string
 	fetchedAt      time.Time
+	lastReadAt     time.Time
+	// refreshAt, if set, replaces the cache TTL, see WithRotationAwareTTL.
+	refreshAt time.Time
+	// ttlJitter is added to the cache TTL, see WithCacheTTLJitter.
+	ttlJitter time.Duration
 }
 
+// ExpiryMode defines how the cache TTL of an entry is measured.
+type ExpiryMode int
+
+const (
+	// ExpireAbsolute expires an entry cacheTTL after it was fetched. This is the default.
+	ExpireAbsolute ExpiryMode = iota
+	// ExpireSliding expires an entry cacheTTL after it was last read, so entries that are
+	// read continuously are never refreshed. Only use it for secrets whose version is known
+	// to be stable, e.g. because rotation is signalled out of band.
+	ExpireSliding
+)
+
 // Option defines a functional option for configuring SecretsManager.
 type Option func(manager *SecretsManager)
 
@@ -71,58 +244,232 @@ func WithCacheTTL(ttl time.Duration) Option {
 	}
 }
 
+// WithRetry allows the retry policy for fetching the secret to be set.
+// Non-positive values keep the respective default.
+func WithRetry(maxAttempts int, initialDelay, maxDelay time.Duration) Option {
+	return func(s *SecretsManager) {
+		if maxAttempts > 0 {
+			s.maxAttempts = maxAttempts
+		}
+		if initialDelay > 0 {
+			s.initialDelay = initialDelay
+		}
+		if maxDelay > 0 {
+			s.maxDelay = maxDelay
+		}
+	}
+}
+
+// WithStartupFetchJitter delays the first fetch of the secret by a random duration up to maxDelay.
+// This spreads the initial GetSecretValue calls of a fleet that is deployed at once.
+func WithStartupFetchJitter(maxDelay time.Duration) Option {
+	return func(s *SecretsManager) {
+		s.startupJitter = maxDelay
+	}
+}
+
+// WithCacheTTLJitter randomizes the cache TTL of each refresh by up to ±fraction of it, e.g. 0.1
+// for ±10%, so that instances started at the same time do not all refresh at the same instant
+// and get throttled together. The fraction must be in [0, 1); otherwise creating the
+// SecretsManager fails with ErrInvalidConfig.
+func WithCacheTTLJitter(fraction float64) Option {
+	return func(s *SecretsManager) {
+		if fraction < 0 || fraction >= 1 {
+			s.optionErrs = append(s.optionErrs, fmt.Errorf("%w: cache TTL jitter must be in [0, 1), got %g", ErrInvalidConfig, fraction))
+			return
+		}
+		s.cacheTTLJitter = fraction
+	}
+}
+
+// WithSoftTTL allows a soft TTL to be set. Entries older than the soft TTL are still
+// served from the cache, but trigger a refresh in the background. Entries older than
+// the cache TTL, which acts as the hard TTL, are refreshed before being served.
+func WithSoftTTL(ttl time.Duration) Option {
+	return func(s *SecretsManager) {
+		s.softTTL = ttl
+	}
+}
+
+// WithExpiryMode allows the cache expiry mode to be set.
+func WithExpiryMode(mode ExpiryMode) Option {
+	return func(s *SecretsManager) {
+		s.expiryMode = mode
+	}
+}
+
+// WithDeadlineFallback allows a stale cached value to be served when a refresh fails
+// because the caller's context deadline was exceeded, as long as the value expired
+// less than grace ago.
+func WithDeadlineFallback(grace time.Duration) Option {
+	return func(s *SecretsManager) {
+		s.deadlineFallback = grace
+	}
+}
+
+// WithRefreshTimeout limits how long a refresh of the secret, including retries, may take. It
+// defaults to 30 seconds. A refresh is shared by all concurrent callers, so it is not canceled
+// when one of them gives up; each caller still stops waiting when its own context is done.
+// Non-positive values keep the default.
+func WithRefreshTimeout(timeout time.Duration) Option {
+	return func(s *SecretsManager) {
+		if timeout > 0 {
+			s.refreshTimeout = timeout
+		}
+	}
+}
+
+// WithClientLogMode enables logging of the given AWS SDK events, such as requests,
+// responses and retries, on the default AWS clients. Messages are written to the logger
+// set with WithLogger or WithSlogLogger, at Debug level, or to the logger of the AWS
+// config if none is set. Unlike the wrapper's own messages, response bodies logged with
+// aws.LogResponseWithBody contain the secret values.
+func WithClientLogMode(mode aws.ClientLogMode) Option {
+	return func(s *SecretsManager) {
+		s.clientLogMode = mode
+	}
+}
+
 // WithSecretsManagerClient allows overriding the default Secrets Manager client for testing purposes.
 func WithSecretsManagerClient(client Client) Option {
 	return func(s *SecretsManager) {
-		s.secretsManagerClient = client
+		s.clients.secretsManager = client
 	}
 }
 
 // WithKMSClient allows overriding the default KMS client for testing purposes.
 func WithKMSClient(client KMSClient) Option {
 	return func(s *SecretsManager) {
-		s.kmsClient = client
+		s.clients.kms = client
 	}
 }
 
 // NewSecretsManager creates a new SecretsManager.
+// If kmsKeyID is an alias, such as "alias/myapp", and the KMS client implements KMSKeyDescriber,
+// the alias is resolved to the key ARN once and validated to point to an enabled key.
+// The configuration is validated as by Builder.Build; invalid configurations fail with
+// ErrInvalidConfig.
+//
+// Deprecated: Use NewBuilder, which also takes a context for the AWS calls made while building.
 func NewSecretsManager(region, secretName, kmsKeyID string, opts ...Option) (*SecretsManager, error) {
-	ctx := context.Background()
-
-	// Load AWS config.
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
-	if err != nil {
+	secretsManager := newSecretsManager(region, secretName, kmsKeyID, opts)
+	if err := secretsManager.validate(); err != nil {
 		return nil, err
 	}
+	if err := secretsManager.init(context.Background()); err != nil {
+		return nil, err
+	}
+	return secretsManager, nil
+}
 
-	// Create default AWS clients.
-	defaultSecretsManagerClient := secretsmanager.NewFromConfig(cfg)
-	defaultKMSClient := kms.NewFromConfig(cfg)
-
+// newSecretsManager returns a SecretsManager with the defaults and opts applied.
+func newSecretsManager(region, secretName, kmsKeyID string, opts []Option) *SecretsManager {
 	secretsManager := &SecretsManager{
-		region:               region,
-		secretName:           secretName,
-		kmsKeyID:             kmsKeyID,
-		ctx:                  ctx,
-		secretsManagerClient: defaultSecretsManagerClient,
-		kmsClient:            defaultKMSClient,
-		maxAttempts:          3,
-		initialDelay:         500 * time.Millisecond,
-		maxDelay:             5 * time.Second,
-		cache:                make(map[string]cachedSecret),
-		cacheTTL:             defaultCacheTTL,
+		region:       region,
+		secretName:   secretName,
+		kmsKeyID:     kmsKeyID,
+		maxAttempts:  3,
+		initialDelay: 500 * time.Millisecond,
+		maxDelay:     5 * time.Second,
+		clients:      &clientSet{},
+		cache:        make(map[string]cachedSecret),
+		cacheTTL:     defaultCacheTTL,
+		startedAt:    time.Now(),
+
+		refreshTimeout: defaultRefreshTimeout,
 	}
 
 	// Apply options; if options are passed, they override the default.
 	for _, opt := range opts {
 		opt(secretsManager)
 	}
+	secretsManager.applyLogLevel()
+	return secretsManager
+}
 
-	return secretsManager, nil
+// init resolves the configuration of s and builds its AWS clients, using ctx for the AWS calls.
+func (s *SecretsManager) init(ctx context.Context) error {
+	if err := errors.Join(s.optionErrs...); err != nil {
+		return err
+	}
+	if err := s.resolveEnvironment(); err != nil {
+		return err
+	}
+	if err := s.resolveARNRegion(); err != nil {
+		return err
+	}
+
+	if !s.hasClients() {
+		if err := s.buildClients(ctx); err != nil {
+			return err
+		}
+	}
+
+	// Resolve a KMS alias once, so a missing alias or disabled key surfaces here
+	// rather than on the first Encrypt.
+	describer, ok := s.kmsAPI().(KMSKeyDescriber)
+	if ok && s.usesKMSKey() && isKMSAlias(s.kmsKeyID) {
+		keyARN, err := ResolveKMSKey(ctx, describer, s.kmsKeyID)
+		if err != nil {
+			return err
+		}
+		s.kmsKeyID = keyARN
+	}
+	return nil
+}
+
+// ResetIdentity reloads the AWS config, rebuilds the default AWS clients and purges the cache.
+// Call it after the underlying AWS credentials changed, e.g. after re-assuming a role,
+// so that no value fetched under the previous identity is served afterwards.
+// Clients injected through options are kept as they are. For a secret of a MultiSecretsManager,
+// the clients of all its secrets are rebuilt, as they share them.
+func (s *SecretsManager) ResetIdentity(ctx context.Context) error {
+	if err := s.buildClients(ctx); err != nil {
+		return err
+	}
+	s.purgeCache()
+	return nil
+}
+
+// purgeCache drops all cached values, so the next read fetches the secret from AWS.
+func (s *SecretsManager) purgeCache() {
+	s.cacheLock.Lock()
+	defer s.cacheLock.Unlock()
+	s.cache = make(map[string]cachedSecret)
+	s.keySet = cachedSecret{}
+	s.payload = cachedSecret{}
+	s.binary = cachedSecret{}
+	s.versionID = ""
+	s.contentHash = ""
+}
+
+// waitStartupJitter delays the first fetch by a random duration up to the startup jitter.
+func (s *SecretsManager) waitStartupJitter(ctx context.Context) error {
+	if s.startupJitter <= 0 {
+		return nil
+	}
+	var err error
+	s.startupJitterOnce.Do(func() {
+		err = sleepContext(ctx, rand.N(s.startupJitter))
+	})
+	return err
+}
+
+// sleepContext pauses for d, or until ctx is done, in which case it returns ctx.Err().
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 // retry retries the given operation with exponential backoff.
-func (s *SecretsManager) retry(operation func() (map[string]string, error)) (map[string]string, error) {
+// It gives up as soon as ctx is done, returning ctx.Err() wrapped with the last error.
+func (s *SecretsManager) retry(ctx context.Context, operation func() (*fetchedSecret, error)) (*fetchedSecret, error) {
 	delay := s.initialDelay
 	var lastErr error
 	for i := 0; i < s.maxAttempts; i++ {
@@ -131,7 +478,22 @@ func (s *SecretsManager) retry(operation func() (map[string]string, error)) (map
 			return result, nil
 		}
 		lastErr = err
-		time.Sleep(delay)
+		s.attemptErr.Store(&err)
+		if isThrottling(err) {
+			fetchScheduler.throttled(time.Now().Add(s.maxDelay))
+		}
+		s.log().Debug(ctx, "fetch attempt failed", "secret", s.secretName, "attempt", i+1,
+			"maxAttempts", s.maxAttempts, "error", err)
+		// Retrying cannot help for permanent errors, such as expired credentials or a deleted secret.
+		if !isRetryable(err) || errors.Is(err, ErrSecretScheduledForDeletion) || i == s.maxAttempts-1 {
+			break
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			if errors.Is(lastErr, err) {
+				return nil, lastErr
+			}
+			return nil, fmt.Errorf("%w (last error: %w)", err, lastErr)
+		}
 		delay *= 2
 		if delay > s.maxDelay {
 			delay = s.maxDelay
@@ -140,40 +502,218 @@ func (s *SecretsManager) retry(operation func() (map[string]string, error)) (map
 	return nil, lastErr
 }
 
+// fetchedSecret is a secret as retrieved from AWS Secrets Manager.
+type fetchedSecret struct {
+	values    map[string]string
+	versionID string
+	// secretString and binary hold the raw payload of a string or binary secret.
+	secretString string
+	binary       []byte
+}
+
 // fetchSecrets retrieves the entire secret from AWS Secrets Manager.
-func (s *SecretsManager) fetchSecrets() (map[string]string, error) {
-	ctx := context.Background()
-	operation := func() (map[string]string, error) {
-		out, err := s.secretsManagerClient.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
-			SecretId: &s.secretName,
-		})
+func (s *SecretsManager) fetchSecrets(ctx context.Context) (_ *fetchedSecret, err error) {
+	ctx, span := s.startSpan(ctx, "secretsmanager.FetchSecret", attrSecretName.String(s.secretName))
+	defer func() { endSpan(span, err) }()
+
+	secret, err := s.fetchSecret(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     &s.secretName,
+		VersionId:    nonEmpty(s.pinnedVersionID),
+		VersionStage: nonEmpty(s.versionStage),
+	})
+	if err != nil {
+		return nil, err
+	}
+	secret, err = s.checkReplicaLag(ctx, secret)
+	if err != nil || !s.resolveRefs {
+		return secret, err
+	}
+	secret.values, err = s.resolveReferences(ctx, secret.values)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// fetchSecret retrieves the secret version selected by input from AWS Secrets Manager.
+func (s *SecretsManager) fetchSecret(ctx context.Context, input *secretsmanager.GetSecretValueInput) (*fetchedSecret, error) {
+	return s.fetchSecretFrom(ctx, s.secretsManagerAPI, input)
+}
+
+// fetchSecretFrom is fetchSecret, reading through the client that api returns.
+func (s *SecretsManager) fetchSecretFrom(ctx context.Context, api func() Client, input *secretsmanager.GetSecretValueInput) (*fetchedSecret, error) {
+	secretID := aws.ToString(input.SecretId)
+	operation := func() (*fetchedSecret, error) {
+		out, err := api().GetSecretValue(ctx, input)
 		if err != nil {
-			return nil, err
+			return nil, s.checkScheduledForDeletion(ctx, secretID, err)
+		}
+		if (out.SecretString == nil || *out.SecretString == "") && s.binarySecrets && len(out.SecretBinary) > 0 {
+			secret, err := s.decodeBinarySecret(out.SecretBinary)
+			if err != nil {
+				return nil, err
+			}
+			secret.versionID = aws.ToString(out.VersionId)
+			return secret, nil
 		}
 		if out.SecretString == nil || *out.SecretString == "" {
-			errString := fmt.Sprintf("secret %q is nil or empty", s.secretName)
+			errString := fmt.Sprintf("secret %q is nil or empty", secretID)
 			err = errors.New(errString)
 			return nil, err
 		}
-		var result map[string]string
-		if err := json.Unmarshal([]byte(*out.SecretString), &result); err != nil {
+		payload, sanitized := sanitizePayload([]byte(*out.SecretString))
+		if sanitized {
+			s.log().Info(ctx, "stripped byte order mark or control characters around secret payload", "secret", secretID)
+		}
+		if err := s.checkPayload(payload); err != nil {
 			return nil, err
 		}
-		return result, nil
+		values, err := parseSecret(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse secret %q: %w", secretID, err)
+		}
+		return &fetchedSecret{values: values, versionID: aws.ToString(out.VersionId), secretString: string(payload)}, nil
+	}
+
+	return s.retry(ctx, operation)
+}
+
+// parseSecret decodes a secret payload, a JSON object or array, into its key–value pairs.
+// Nested objects and arrays are flattened into dotted paths, such as "db.credentials.password";
+// array elements are keyed by their index, so a JSON array of objects yields "<index>.<field>" keys.
+// Values that are not JSON strings are kept as their compact JSON text, e.g. "5432" or "true".
+// Payloads in which two fields flatten to the same key, such as a literal "a.b" field next to
+// {"a":{"b":...}}, are rejected, as either could win. Errors include the shape of the payload, see payloadShape, but none of its keys or values.
+func parseSecret(data []byte) (map[string]string, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || (trimmed[0] != '{' && trimmed[0] != '[') {
+		return nil, fmt.Errorf("secret is not a JSON object or array (payload shape %q)", payloadShape(data))
+	}
+	result := make(map[string]string)
+	if err := flatten(result, "", trimmed); err != nil {
+		return nil, fmt.Errorf("%w (payload shape %q)", err, payloadShape(data))
+	}
+	return result, nil
+}
+
+// flatten adds the leaves of the JSON value raw at path to result.
+// Empty objects and arrays are kept as leaves, so their keys do not disappear. A leaf whose path
+// is already in result is an error.
+func flatten(result map[string]string, path string, raw json.RawMessage) error {
+	switch raw[0] {
+	case '{':
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return err
+		}
+		if len(fields) > 0 || path == "" {
+			for k, v := range fields {
+				if err := flatten(result, joinPath(path, k), v); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case '[':
+		var elements []json.RawMessage
+		if err := json.Unmarshal(raw, &elements); err != nil {
+			return err
+		}
+		if len(elements) > 0 || path == "" {
+			for i, v := range elements {
+				if err := flatten(result, joinPath(path, strconv.Itoa(i)), v); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	v, err := rawValue(raw)
+	if err != nil {
+		return err
+	}
+	if _, ok := result[path]; ok {
+		return errors.New("secret has fields that flatten to the same key")
 	}
+	result[path] = v
+	return nil
+}
 
-	return s.retry(operation)
+// rawValue returns the string held by a JSON string, or the compact JSON text of any other value.
+func rawValue(raw json.RawMessage) (string, error) {
+	if raw[0] == '"' {
+		var v string
+		err := json.Unmarshal(raw, &v)
+		return v, err
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// elementKey returns the key under which field of the i-th array element is cached.
+func elementKey(i int, field string) string {
+	return joinPath(strconv.Itoa(i), field)
 }
 
 // Get retrieves the individual secret value for the given key.
 // It refreshes the entire secret from AWS if the cache is expired.
 func (s *SecretsManager) Get(key string) (string, error) {
+	return s.GetWithContext(context.Background(), key)
+}
+
+// GetWithContext is like Get, but uses ctx for the AWS calls it makes.
+// If WithDeadlineFallback is set and the refresh fails because ctx's deadline
+// was exceeded, the stale cached value is returned instead.
+func (s *SecretsManager) GetWithContext(ctx context.Context, key string) (string, error) {
+	defer s.checkBudget(ctx, OperationGet, time.Now())
+	if s.tracer == nil {
+		// Skip building the span attributes, which would allocate on every read.
+		return s.get(ctx, key)
+	}
+	ctx, span := s.startSpan(ctx, "secretsmanager.Get", attrSecretName.String(s.secretName), attrKey.String(key))
+	val, err := s.get(ctx, key)
+	endSpan(span, err)
+	return val, err
+}
+
+// get implements GetWithContext.
+func (s *SecretsManager) get(ctx context.Context, key string) (string, error) {
+	if err := s.checkAccess(ctx, key); err != nil {
+		return "", err
+	}
+	return s.lookup(ctx, key)
+}
+
+// lookup is get without the access check.
+func (s *SecretsManager) lookup(ctx context.Context, key string) (string, error) {
 	// Check local cache first.
 	s.cacheLock.RLock()
-	if cs, ok := s.cache[key]; ok && time.Since(cs.fetchedAt) < s.cacheTTL {
-		s.cacheLock.RUnlock()
+	cs, cached := s.cache[key]
+	s.cacheLock.RUnlock()
+	hit := cached && s.isFresh(cs)
+	if hit {
+		s.cacheHits.Add(1)
+	} else {
+		s.cacheMisses.Add(1)
+	}
+	if s.tracer != nil || s.logger != nil {
+		s.recordLookup(ctx, key, hit)
+	}
+	if hit {
+		s.touch(key, cs)
+		if s.softTTL > 0 && time.Since(cs.fetchedAt) >= s.softTTL {
+			// Serve the cached value, but refresh it for subsequent calls.
+			s.refreshInBackground(context.WithoutCancel(ctx))
+		}
 		// Decrypt the cached value.
-		plaintext, err := DecryptValue(context.Background(), s.kmsClient, cs.encryptedValue)
+		var plaintext string
+		err := s.withFreshCredentials(ctx, func() (err error) {
+			plaintext, err = s.cacheCipher().Decrypt(ctx, cs.encryptedValue)
+			return err
+		})
 		if err != nil {
 			errString := fmt.Sprintf("failed to decrypt cached value for %s", key)
 			err = errors.New(errString)
@@ -181,69 +721,382 @@ func (s *SecretsManager) Get(key string) (string, error) {
 		}
 		return plaintext, nil
 	}
-	s.cacheLock.RUnlock()
-
-	// Cache miss: fetch the entire secret from AWS.
-	secretsMap, err := s.fetchSecrets()
-	if err != nil {
-		return "", err
-	}
 
-	// Update cache for each key.
-	s.cacheLock.Lock()
-	defer s.cacheLock.Unlock()
-	for k, v := range secretsMap {
-		// Encrypt the value using KMS.
-		enc, err := EncryptValue(context.Background(), s.kmsClient, s.kmsKeyID, v)
-		if err != nil {
+	// Cache miss: fetch the entire secret from AWS, unless the key is known to be missing from it.
+	if cached || !s.keySetFresh() {
+		if err := s.refresh(ctx); err != nil {
+			if cached && s.canServeStale(cs, err) {
+				return s.cacheCipher().Decrypt(ctx, cs.encryptedValue)
+			}
 			return "", err
 		}
-		s.cache[k] = cachedSecret{
-			encryptedValue: enc,
-			fetchedAt:      time.Now(),
-		}
 	}
 
 	// Retrieve the requested key.
+	s.cacheLock.RLock()
 	cs, ok := s.cache[key]
+	s.cacheLock.RUnlock()
 	if !ok {
-		errString := fmt.Sprintf("%s: secret not found", key)
-		err = errors.New(errString)
-		return "", err
+		if val, ok := s.defaults[key]; ok {
+			s.log().Info(ctx, "key missing from secret; using default", "secret", s.secretName, "key", key)
+			return val, nil
+		}
+		return "", fmt.Errorf("%s: %w", key, ErrSecretNotFound)
 	}
-	plaintext, err := DecryptValue(context.Background(), s.kmsClient, cs.encryptedValue)
+	plaintext, err := s.cacheCipher().Decrypt(ctx, cs.encryptedValue)
 	if err != nil {
 		return "", err
 	}
 	return plaintext, nil
 }
 
-// Watch starts a background goroutine to poll for changes in the entire secret
-// and calls the callback if the value for the given key changes.
-func (s *SecretsManager) Watch(ctx context.Context, key string, interval time.Duration, callback func(newVal string)) {
-	go func() {
-		// Perform an initial fetch and set lastVal.
-		lastVal, err := s.Get(key)
+// recordLookup records a read of key on the span in ctx and in the log, attributed to its principal
+// and with its call metadata.
+func (s *SecretsManager) recordLookup(ctx context.Context, key string, hit bool) {
+	principal := s.principal(ctx)
+	md := MetadataFromContext(ctx)
+	if s.tracer != nil {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attrCacheHit.Bool(hit))
+		if principal.ID != "" {
+			span.SetAttributes(attrEndUserID.String(principal.ID))
+		}
+		for k, v := range md {
+			span.SetAttributes(attribute.String(attrMetadataPrefix+k, v))
+		}
+	}
+	if s.logger != nil {
+		args := []any{"secret", s.secretName, "key", key, "cacheHit", hit}
+		if principal.ID != "" {
+			args = append(args, "principal", principal.ID, "principalType", principal.Type)
+		}
+		if len(md) > 0 {
+			args = append(args, "metadata", md)
+		}
+		s.logger.Debug(ctx, "secret lookup", args...)
+	}
+}
+
+// refresh fetches the entire secret from AWS and updates the cache for each key.
+// If the AWS credentials expired, the clients are rebuilt and the refresh is tried once more.
+// Concurrent calls share a single refresh. It runs with the values of the first caller's context,
+// but is neither canceled with it nor bound by its deadline, only by the refresh timeout, so one
+// impatient caller cannot fail the others. Each caller stops waiting when its own ctx is done.
+// As refreshes never overlap, an older version can never replace a newer one in the cache.
+func (s *SecretsManager) refresh(ctx context.Context) error {
+	if err := s.waitStartupJitter(ctx); err != nil {
+		return err
+	}
+	ch := s.refreshGroup.DoChan(s.secretName, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.refreshTimeout)
+		defer cancel()
+		s.attemptErr.Store(nil)
+		release, err := s.acquireColdStartSlot(ctx)
 		if err != nil {
-			return
+			return nil, err
+		}
+		defer release()
+		releaseFetch, err := fetchScheduler.acquire(ctx, s.priority)
+		if err != nil {
+			return nil, err
 		}
+		defer releaseFetch()
+		defer s.checkBudget(ctx, OperationRefresh, time.Now())
+		return nil, s.withFreshCredentials(ctx, func() error {
+			return s.refreshCache(ctx)
+		})
+	})
+	select {
+	case res := <-ch:
+		return res.Err
+	case <-ctx.Done():
+		if lastErr := s.attemptErr.Load(); lastErr != nil {
+			return fmt.Errorf("%w (last error: %w)", ctx.Err(), *lastErr)
+		}
+		return ctx.Err()
+	}
+}
 
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				val, err := s.Get(key)
-				if err != nil {
-					continue
-				}
-				if val != lastVal {
-					lastVal = val
-					callback(val)
-				}
+// refreshCache fetches the entire secret from AWS and updates the cache for each key.
+func (s *SecretsManager) refreshCache(ctx context.Context) error {
+	var secret *fetchedSecret
+	var err error
+	s.withLabels(ctx, "fetch", func(ctx context.Context) {
+		secret, err = s.fetchSecrets(ctx)
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.checkCanary(ctx, secret); err != nil {
+		return err
+	}
+	secretsMap := secret.values
+	c, err := s.refreshCipher(ctx)
+	if err != nil {
+		return err
+	}
+	refreshAt := s.rotationRefreshAt(ctx)
+
+	// Encrypt everything aside and swap it in at once, so a failure leaves the cache untouched.
+	// Identical values, such as a token shared by several keys, are encrypted and stored once.
+	encrypted := make(map[string]string, len(secretsMap))
+	byPlaintext := make(map[string]string, len(secretsMap))
+	for k, v := range secretsMap {
+		enc, ok := byPlaintext[v]
+		if !ok {
+			// Encrypt the value with the cache cipher.
+			if enc, err = c.Encrypt(ctx, v); err != nil {
+				return err
 			}
+			byPlaintext[v] = enc
+		}
+		encrypted[k] = enc
+	}
+	var encPayload, encBinary string
+	if secret.secretString != "" {
+		if encPayload, err = c.Encrypt(ctx, secret.secretString); err != nil {
+			return err
+		}
+	}
+	if secret.binary != nil {
+		if encBinary, err = c.Encrypt(ctx, string(secret.binary)); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	// All entries share the jitter, so they still expire together and take a single fetch.
+	jitter := s.randomTTLJitter()
+	entry := func(enc string) cachedSecret {
+		return cachedSecret{encryptedValue: enc, fetchedAt: now, lastReadAt: now, refreshAt: refreshAt, ttlJitter: jitter}
+	}
+	cache := make(map[string]cachedSecret, len(encrypted))
+	for k, enc := range encrypted {
+		cache[k] = entry(enc)
+	}
+
+	s.cacheLock.Lock()
+	defer s.cacheLock.Unlock()
+	s.cache = cache
+	s.keySet = entry("")
+	s.payload = cachedSecret{}
+	if secret.secretString != "" {
+		s.payload = entry(encPayload)
+	}
+	s.binary = cachedSecret{}
+	if secret.binary != nil {
+		s.binary = entry(encBinary)
+	}
+	if s.versionID != secret.versionID {
+		s.log().Info(ctx, "secret refreshed", "secret", s.secretName, "version", secret.versionID)
+	}
+	s.versionID = secret.versionID
+	s.contentHash = secret.contentHash()
+	s.fetched.Store(true)
+	return nil
+}
+
+// ContentHash returns a hex-encoded SHA-256 hash of the cached secret payload, or an empty string
+// if nothing has been fetched yet. It only depends on the keys and values, so it is stable across
+// processes and changes whenever any value changes; compare it to detect that anything changed.
+func (s *SecretsManager) ContentHash() string {
+	s.cacheLock.RLock()
+	defer s.cacheLock.RUnlock()
+	return s.contentHash
+}
+
+// contentHash hashes the key–value pairs in sorted order, followed by the binary payload.
+func (f *fetchedSecret) contentHash() string {
+	keys := make([]string, 0, len(f.values))
+	for k := range f.values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		// Length prefixes keep the encoding unambiguous.
+		_, _ = fmt.Fprintf(h, "%d:%s%d:%s", len(k), k, len(f.values[k]), f.values[k])
+	}
+	_, _ = h.Write(f.binary)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Version returns the VersionId of the secret version currently cached,
+// or an empty string if nothing has been fetched yet.
+func (s *SecretsManager) Version() string {
+	s.cacheLock.RLock()
+	defer s.cacheLock.RUnlock()
+	return s.versionID
+}
+
+// ensureFresh refreshes the cache unless it holds only fresh entries.
+func (s *SecretsManager) ensureFresh(ctx context.Context) error {
+	s.cacheLock.RLock()
+	fresh := len(s.cache) > 0
+	for _, cs := range s.cache {
+		if !s.isFresh(cs) {
+			fresh = false
+			break
+		}
+	}
+	s.cacheLock.RUnlock()
+	if fresh {
+		return nil
+	}
+	return s.refresh(ctx)
+}
+
+// keySetFresh reports whether the keys in the cache are those of the secret, as fetched within the
+// cache TTL, so a key that is not cached is missing from the secret.
+func (s *SecretsManager) keySetFresh() bool {
+	s.cacheLock.RLock()
+	defer s.cacheLock.RUnlock()
+	return s.isFresh(s.keySet)
+}
+
+// refreshInBackground starts an asynchronous refresh, unless one is already running.
+func (s *SecretsManager) refreshInBackground(ctx context.Context) {
+	if !s.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.refreshing.Store(false)
+		// The next Get past the hard TTL retries synchronously.
+		if err := s.refresh(ctx); err != nil {
+			s.log().Error(ctx, "background refresh failed", "secret", s.secretName, "error", err)
 		}
 	}()
 }
+
+// GetWhere retrieves a field from a secret that holds a JSON array of objects, such as
+// [{"name":"svcA","token":"..."}]. It returns field of the first element whose matchField
+// equals matchValue. Every element is expected to have matchField.
+// Individual elements can also be read directly with Get("<index>.<field>").
+// The elements are scanned in the cache, which is only refreshed if it is expired.
+func (s *SecretsManager) GetWhere(matchField, matchValue, field string) (string, error) {
+	ctx := context.Background()
+	if err := s.ensureFresh(ctx); err != nil {
+		return "", err
+	}
+
+	// Take a snapshot of the elements, so they are decrypted without holding the lock.
+	type element struct {
+		match, field cachedSecret
+		hasField     bool
+	}
+	var elements []element
+	s.cacheLock.RLock()
+	for i := 0; ; i++ {
+		match, ok := s.cache[elementKey(i, matchField)]
+		if !ok {
+			break
+		}
+		f, hasField := s.cache[elementKey(i, field)]
+		elements = append(elements, element{match: match, field: f, hasField: hasField})
+	}
+	s.cacheLock.RUnlock()
+
+	cipher := s.cacheCipher()
+	for i, e := range elements {
+		if err := s.checkAccess(ctx, elementKey(i, matchField)); err != nil {
+			return "", err
+		}
+		val, err := cipher.Decrypt(ctx, e.match.encryptedValue)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt cached value for %s: %w", elementKey(i, matchField), err)
+		}
+		if val != matchValue {
+			continue
+		}
+		if !e.hasField {
+			return "", fmt.Errorf("%s: %w", elementKey(i, field), ErrSecretNotFound)
+		}
+		if err := s.checkAccess(ctx, elementKey(i, field)); err != nil {
+			return "", err
+		}
+		return cipher.Decrypt(ctx, e.field.encryptedValue)
+	}
+	return "", fmt.Errorf("no element with %s=%q: %w", matchField, matchValue, ErrSecretNotFound)
+}
+
+// expiryBase returns the time from which the TTL of cs is measured.
+func (s *SecretsManager) expiryBase(cs cachedSecret) time.Time {
+	if s.expiryMode == ExpireSliding {
+		return cs.lastReadAt
+	}
+	return cs.fetchedAt
+}
+
+// isFresh reports whether cs is still within the cache TTL.
+func (s *SecretsManager) isFresh(cs cachedSecret) bool {
+	return time.Since(s.expiryBase(cs)) < s.ttl(cs)
+}
+
+// ttl returns the cache TTL of cs: until its refreshAt if set, extended to the cold-start window if
+// cs was fetched within it, or cacheTTL plus its jitter otherwise.
+func (s *SecretsManager) ttl(cs cachedSecret) time.Duration {
+	if !cs.refreshAt.IsZero() {
+		return cs.refreshAt.Sub(s.expiryBase(cs))
+	}
+	if s.coldStartWindow > s.cacheTTL && s.inColdStart(cs.fetchedAt) {
+		return s.coldStartWindow
+	}
+	return s.cacheTTL + cs.ttlJitter
+}
+
+// randomTTLJitter returns a random offset for the cache TTL within ±cacheTTLJitter of it.
+func (s *SecretsManager) randomTTLJitter() time.Duration {
+	if s.cacheTTLJitter <= 0 {
+		return 0
+	}
+	return time.Duration((2*rand.Float64() - 1) * s.cacheTTLJitter * float64(s.cacheTTL))
+}
+
+// touch records a read of cs under key when sliding expiry is enabled.
+func (s *SecretsManager) touch(key string, cs cachedSecret) {
+	if s.expiryMode != ExpireSliding {
+		return
+	}
+	s.cacheLock.Lock()
+	defer s.cacheLock.Unlock()
+	// Only extend the entry that was read, not one that was refreshed in the meantime.
+	if current, ok := s.cache[key]; ok && current.fetchedAt.Equal(cs.fetchedAt) {
+		current.lastReadAt = time.Now()
+		s.cache[key] = current
+	}
+}
+
+// canServeStale reports whether cs may be served after a refresh failed with err.
+// Values whose new version failed the canary check are always served.
+func (s *SecretsManager) canServeStale(cs cachedSecret, err error) bool {
+	if errors.Is(err, ErrCanaryCheckFailed) {
+		return true
+	}
+	if s.deadlineFallback <= 0 || !errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return time.Since(s.expiryBase(cs)) < s.ttl(cs)+s.deadlineFallback
+}
+
+// Watch starts a background goroutine to poll for changes in the entire secret
+// and calls the callback if the value for the given key changes.
+// ctx is passed to every AWS call the watcher makes, and stops the watcher when done.
+// If the initial fetch fails, it is retried at every interval until it succeeds.
+// An error is logged when the secret turns out to be scheduled for deletion.
+// The returned Watcher stops and inspects this watcher alone.
+func (s *SecretsManager) Watch(ctx context.Context, key string, interval time.Duration, callback func(newVal string), opts ...WatchOption) *Watcher {
+	var lastVal string
+	initialized := false
+	return s.startWatcher(ctx, interval, opts, func(ctx context.Context) (string, error) {
+		val, err := s.GetWithContext(ctx, key)
+		if err != nil {
+			return "", err
+		}
+		if initialized && val != lastVal {
+			callback(val)
+		}
+		lastVal, initialized = val, true
+		return val, nil
+	})
+}
@@ -3,7 +3,11 @@ package secretsmanager_test
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash/crc32"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -11,6 +15,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/smithy-go"
 	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
 	"github.com/stretchr/testify/require"
 )
@@ -25,20 +31,121 @@ type mockSecretsManagerClient struct {
 	callCount int32
 	// err can simulate errors.
 	err error
+	// delay simulates a slow response; the call is aborted if ctx is done first.
+	delay time.Duration
+	// requestedSecretID holds the SecretId of the last request.
+	requestedSecretID atomic.Value
 }
 
 // GetSecretValue simulates the AWS SDK GetSecretValue method.
-func (m *mockSecretsManagerClient) GetSecretValue(_ context.Context, _ *awsSecretsManager.GetSecretValueInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
+func (m *mockSecretsManagerClient) GetSecretValue(ctx context.Context, input *awsSecretsManager.GetSecretValueInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
 	atomic.AddInt32(&m.callCount, 1)
+	m.requestedSecretID.Store(aws.ToString(input.SecretId))
 	if m.err != nil {
 		return nil, m.err
 	}
+	if m.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(m.delay):
+		}
+	}
 	val := m.secretValue.Load().(string)
 	return &awsSecretsManager.GetSecretValueOutput{
 		SecretString: aws.String(val),
+		VersionId:    aws.String(versionOf(val)),
+	}, nil
+}
+
+// versionOf derives a mock VersionId from a secret value, so that the version changes with the value.
+func versionOf(val string) string {
+	return fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(val)))
+}
+
+// mockSecretResponse is a single response of mockSequencedSecretsManagerClient.
+type mockSecretResponse struct {
+	value   string
+	version string
+	delay   time.Duration
+	err     error
+	// binary is returned as SecretBinary instead of value.
+	binary []byte
+}
+
+// mockSequencedSecretsManagerClient simulates a Secrets Manager client that returns
+// the given responses in order, each after its own delay.
+type mockSequencedSecretsManagerClient struct {
+	responses []mockSecretResponse
+	callCount int32
+}
+
+// GetSecretValue simulates the AWS SDK GetSecretValue method.
+func (m *mockSequencedSecretsManagerClient) GetSecretValue(_ context.Context, _ *awsSecretsManager.GetSecretValueInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
+	i := int(atomic.AddInt32(&m.callCount, 1)) - 1
+	response := m.responses[min(i, len(m.responses)-1)]
+	time.Sleep(response.delay)
+	if response.err != nil {
+		return nil, response.err
+	}
+	if response.binary != nil {
+		return &awsSecretsManager.GetSecretValueOutput{
+			SecretBinary: response.binary,
+			VersionId:    aws.String(response.version),
+		}, nil
+	}
+	return &awsSecretsManager.GetSecretValueOutput{
+		SecretString: aws.String(response.value),
+		VersionId:    aws.String(response.version),
+	}, nil
+}
+
+// mockSecretsByIDClient simulates a Secrets Manager client holding several secrets,
+// which can also be written.
+type mockSecretsByIDClient struct {
+	mu sync.Mutex
+	// secrets maps secret IDs to their JSON strings.
+	secrets map[string]string
+	// callCount tracks how many times GetSecretValue is called.
+	callCount int32
+}
+
+// GetSecretValue simulates the AWS SDK GetSecretValue method.
+func (m *mockSecretsByIDClient) GetSecretValue(_ context.Context, input *awsSecretsManager.GetSecretValueInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
+	atomic.AddInt32(&m.callCount, 1)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	val, ok := m.secrets[aws.ToString(input.SecretId)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("secret not found")}
+	}
+	return &awsSecretsManager.GetSecretValueOutput{
+		SecretString: aws.String(val),
+	}, nil
+}
+
+// PutSecretValue simulates the AWS SDK PutSecretValue method.
+func (m *mockSecretsByIDClient) PutSecretValue(_ context.Context, input *awsSecretsManager.PutSecretValueInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.PutSecretValueOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := aws.ToString(input.SecretId)
+	if _, ok := m.secrets[id]; !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("secret not found")}
+	}
+	m.secrets[id] = aws.ToString(input.SecretString)
+	return &awsSecretsManager.PutSecretValueOutput{
+		Name:      input.SecretId,
+		VersionId: aws.String(versionOf(m.secrets[id])),
 	}, nil
 }
 
+// secret returns the current JSON string of the secret with the given ID.
+func (m *mockSecretsByIDClient) secret(id string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.secrets[id]
+}
+
 // mockKMSClient simulates a KMS client that does no real encryption or decryption.
 type mockKMSClient struct{}
 
@@ -214,6 +321,34 @@ func TestSecretsManager_RetryFailure(t *testing.T) {
 	require.Equal(t, int32(3), atomic.LoadInt32(&smMock.callCount))
 }
 
+func TestSecretsManager_RetryOnlyRetryableErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int32
+	}{
+		{name: "not found", err: &types.ResourceNotFoundException{Message: aws.String("not found")}, wantCalls: 1},
+		{name: "access denied", err: &smithy.GenericAPIError{Code: "AccessDeniedException", Fault: smithy.FaultClient}, wantCalls: 1},
+		{name: "throttled", err: &smithy.GenericAPIError{Code: "ThrottlingException"}, wantCalls: 3},
+		{name: "internal error", err: &types.InternalServiceError{Message: aws.String("internal error")}, wantCalls: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			smMock := &mockSecretsManagerClient{err: tt.err}
+			secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+				secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+				secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+				secretsmanagerWrapper.WithRetry(3, time.Millisecond, time.Millisecond),
+			)
+			require.NoError(t, err)
+
+			_, err = secretsManager.Get("DB_PASSWORD")
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.wantCalls, atomic.LoadInt32(&smMock.callCount))
+		})
+	}
+}
+
 func TestSecretsManager_Get_SecretNotFound(t *testing.T) {
 	// Test that if the secret is not found, Get returns an error.
 	validData := map[string]string{
@@ -230,7 +365,446 @@ func TestSecretsManager_Get_SecretNotFound(t *testing.T) {
 	secretsManager := newSecretsManagerForTest(t, smMock, kmsMock, 50*time.Millisecond)
 
 	_, err = secretsManager.Get("NON_EXISTENT_KEY")
-	require.Error(t, err)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+}
+
+func TestSecretsManager_GetWithContext_DeadlineFallback(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"DB_PASSWORD": "stalePassword"})
+	require.NoError(t, err)
+
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+	kmsMock := &mockKMSClient{}
+
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(kmsMock),
+		secretsmanagerWrapper.WithCacheTTL(50*time.Millisecond),
+		secretsmanagerWrapper.WithDeadlineFallback(time.Second),
+	)
+	require.NoError(t, err)
+
+	// Populate the cache, then let it expire.
+	_, err = secretsManager.GetWithContext(context.Background(), "DB_PASSWORD")
+	require.NoError(t, err)
+	time.Sleep(60 * time.Millisecond)
+
+	// Simulate a refresh that cannot complete within the caller's deadline.
+	smMock.delay = time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// The stale value is served instead of a deadline error.
+	val, err := secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "stalePassword", val)
+
+	// Without the fallback, the deadline error is returned. The refresh above still runs in the
+	// background, so use another client.
+	noFallbackMock := &mockSecretsManagerClient{}
+	noFallbackMock.secretValue.Store(string(secretJSON))
+	noFallback := newSecretsManagerForTest(t, noFallbackMock, kmsMock, 50*time.Millisecond)
+	_, err = noFallback.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	time.Sleep(60 * time.Millisecond)
+	noFallbackMock.delay = time.Second
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel2()
+	_, err = noFallback.GetWithContext(ctx2, "DB_PASSWORD")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestSecretsManager_GetWithContext_Canceled(t *testing.T) {
+	smMock := &mockSecretsManagerClient{delay: time.Second}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// Cancelling the context aborts the in-flight call without retrying.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	require.ErrorIs(t, err, context.Canceled)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_GetWithContext_CanceledDuringBackoff(t *testing.T) {
+	smMock := &mockSecretsManagerClient{err: fmt.Errorf("simulated SM error")}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithRetry(5, time.Second, time.Second),
+	)
+	require.NoError(t, err)
+
+	// Cancelling the context cuts the backoff short, and both errors are reported.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorContains(t, err, "simulated SM error")
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_Get_StartupFetchJitter(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithStartupFetchJitter(time.Hour),
+	)
+	require.NoError(t, err)
+
+	// The first fetch waits for the jitter, but gives up when the caller's context is done.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Equal(t, int32(0), atomic.LoadInt32(&smMock.callCount))
+
+	// The jitter only applies once.
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+}
+
+func TestSecretsManager_Get_SlidingExpiry(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"DB_PASSWORD": "password"})
+	require.NoError(t, err)
+
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+	kmsMock := &mockKMSClient{}
+
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(kmsMock),
+		secretsmanagerWrapper.WithCacheTTL(100*time.Millisecond),
+		secretsmanagerWrapper.WithExpiryMode(secretsmanagerWrapper.ExpireSliding),
+	)
+	require.NoError(t, err)
+
+	// Keep reading well within the TTL for longer than the TTL itself.
+	for i := 0; i < 5; i++ {
+		_, err = secretsManager.Get("DB_PASSWORD")
+		require.NoError(t, err)
+		time.Sleep(40 * time.Millisecond)
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+
+	// Once reads stop for longer than the TTL, the entry expires.
+	time.Sleep(120 * time.Millisecond)
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_Get_JSONArray(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`[{"name":"svcA","token":"tokenA"},{"name":"svcB","token":"tokenB"}]`)
+	kmsMock := &mockKMSClient{}
+
+	secretsManager := newSecretsManagerForTest(t, smMock, kmsMock, time.Minute)
+
+	// Elements can be addressed by index.
+	val, err := secretsManager.Get("1.token")
+	require.NoError(t, err)
+	require.Equal(t, "tokenB", val)
+
+	// Or looked up by the value of one of their fields.
+	val, err = secretsManager.GetWhere("name", "svcA", "token")
+	require.NoError(t, err)
+	require.Equal(t, "tokenA", val)
+
+	_, err = secretsManager.GetWhere("name", "svcC", "token")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+
+	// Lookups, including those without a match, are served from the cache.
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_Get_NestedJSON(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"db":{"port":5432,"tls":true,"credentials":{"password":"password"},"hosts":["a","b"],"options":{}}}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// Nested values are read by their dotted path; non-string values as their JSON text.
+	for key, want := range map[string]string{
+		"db.credentials.password": "password",
+		"db.port":                 "5432",
+		"db.tls":                  "true",
+		"db.hosts.1":              "b",
+		"db.options":              "{}",
+	} {
+		val, err := secretsManager.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, want, val, key)
+	}
+
+	// Objects themselves are not cached; use GetInto to decode them.
+	_, err := secretsManager.Get("db.credentials")
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+}
+
+func TestSecretsManager_Get_NestedJSON_Collision(t *testing.T) {
+	// A dotted field and a nested object that flatten to the same key are ambiguous, so the payload
+	// is rejected rather than served with either value, whichever comes last.
+	for _, payload := range []string{
+		`{"db.port":"5432","db":{"port":"6543"}}`,
+		`{"db":{"port":"6543"},"db.port":"5432"}`,
+		`{"hosts.0":"a","hosts":["b"]}`,
+	} {
+		smMock := &mockSecretsManagerClient{}
+		smMock.secretValue.Store(payload)
+		secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+			secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+			secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+			secretsmanagerWrapper.WithRetry(1, time.Millisecond, time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		_, err = secretsManager.Get("DB_PASSWORD")
+		require.ErrorContains(t, err, "flatten to the same key", payload)
+		require.NotContains(t, err.Error(), "5432")
+	}
+}
+
+func TestSecretsManager_Get_SoftTTL(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"DB_PASSWORD": "initialPassword"})
+	require.NoError(t, err)
+
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+	kmsMock := &mockKMSClient{}
+
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(kmsMock),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+		secretsmanagerWrapper.WithSoftTTL(50*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+
+	// Rotate the secret and let the soft TTL pass.
+	updatedJSON, err := json.Marshal(map[string]string{"DB_PASSWORD": "newPassword"})
+	require.NoError(t, err)
+	smMock.secretValue.Store(string(updatedJSON))
+	time.Sleep(60 * time.Millisecond)
+
+	// The cached value is still served, while a refresh runs in the background.
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "initialPassword", val)
+
+	require.Eventually(t, func() bool {
+		val, err := secretsManager.Get("DB_PASSWORD")
+		return err == nil && val == "newPassword"
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_WithCacheTTLJitter(t *testing.T) {
+	// With ±90% jitter on a TTL of 100ms, each instance refreshes after 10ms to 190ms.
+	var managers []*secretsmanagerWrapper.SecretsManager
+	var mocks []*mockSecretsManagerClient
+	for range 50 {
+		smMock := &mockSecretsManagerClient{}
+		smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+		secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+			secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+			secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+			secretsmanagerWrapper.WithCacheTTL(100*time.Millisecond),
+			secretsmanagerWrapper.WithCacheTTLJitter(0.9),
+		)
+		require.NoError(t, err)
+		_, err = secretsManager.Get("DB_PASSWORD")
+		require.NoError(t, err)
+		managers = append(managers, secretsManager)
+		mocks = append(mocks, smMock)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	refreshed := 0
+	for i, secretsManager := range managers {
+		_, err := secretsManager.Get("DB_PASSWORD")
+		require.NoError(t, err)
+		if atomic.LoadInt32(&mocks[i].callCount) == 2 {
+			refreshed++
+		}
+	}
+	// The refreshes are spread out instead of happening all at once.
+	require.Positive(t, refreshed)
+	require.Less(t, refreshed, len(managers))
+}
+
+func TestSecretsManager_WithCacheTTLJitter_Invalid(t *testing.T) {
+	for _, fraction := range []float64{-0.1, 1} {
+		_, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+			secretsmanagerWrapper.WithSecretsManagerClient(&mockSecretsManagerClient{}),
+			secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+			secretsmanagerWrapper.WithCacheTTLJitter(fraction),
+		)
+		require.ErrorIs(t, err, secretsmanagerWrapper.ErrInvalidConfig)
+		require.ErrorContains(t, err, "cache TTL jitter must be in [0, 1)")
+	}
+}
+
+func TestNewSecretsManager_Validates(t *testing.T) {
+	// NewSecretsManager rejects the configurations that Builder.Build rejects.
+	_, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(&mockSecretsManagerClient{}),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(0),
+	)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrInvalidConfig)
+	require.ErrorContains(t, err, "cache TTL must be positive")
+
+	_, err = secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "",
+		secretsmanagerWrapper.WithSecretsManagerClient(&mockSecretsManagerClient{}),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+	)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrInvalidConfig)
+	require.ErrorContains(t, err, "no KMS key is set")
+}
+
+func TestSecretsManager_Version(t *testing.T) {
+	smMock := &mockSequencedSecretsManagerClient{responses: []mockSecretResponse{
+		{value: `{"DB_PASSWORD":"oldPassword"}`, version: "v1"},
+		{value: `{"DB_PASSWORD":"newPassword"}`, version: "v2"},
+	}}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, 20*time.Millisecond)
+	require.Empty(t, secretsManager.Version())
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "oldPassword", val)
+	require.Equal(t, "v1", secretsManager.Version())
+
+	// The version is updated together with the values.
+	time.Sleep(30 * time.Millisecond)
+	val, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "newPassword", val)
+	require.Equal(t, "v2", secretsManager.Version())
+}
+
+func TestSecretsManager_Get_ConcurrentMisses(t *testing.T) {
+	smMock := &mockSecretsManagerClient{delay: 50 * time.Millisecond}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// Concurrent Gets on a cold cache share a single fetch.
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, err := secretsManager.Get("DB_PASSWORD")
+			require.NoError(t, err)
+			require.Equal(t, "password", val)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_Get_CanceledCallerDoesNotFailOthers(t *testing.T) {
+	smMock := &mockSecretsManagerClient{delay: 50 * time.Millisecond}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// The first caller gives up before the shared fetch finishes.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := secretsManager.GetWithContext(ctx, "DB_PASSWORD")
+		done <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	// A concurrent caller with a longer deadline still gets the value from the same fetch.
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+	require.ErrorIs(t, <-done, context.DeadlineExceeded)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_ContentHash(t *testing.T) {
+	smMock := &mockSequencedSecretsManagerClient{responses: []mockSecretResponse{
+		{value: `{"A":"1","B":"2"}`, version: "v1"},
+		// Same content in a different order and version.
+		{value: `{"B":"2","A":"1"}`, version: "v2"},
+		{value: `{"A":"1","B":"3"}`, version: "v3"},
+	}}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, 20*time.Millisecond)
+	require.Empty(t, secretsManager.ContentHash())
+
+	_, err := secretsManager.Get("A")
+	require.NoError(t, err)
+	first := secretsManager.ContentHash()
+	require.Len(t, first, 64)
+
+	// The hash only depends on the content.
+	time.Sleep(30 * time.Millisecond)
+	_, err = secretsManager.Get("A")
+	require.NoError(t, err)
+	require.Equal(t, first, secretsManager.ContentHash())
+
+	// And changes with any value.
+	time.Sleep(30 * time.Millisecond)
+	_, err = secretsManager.Get("A")
+	require.NoError(t, err)
+	require.NotEqual(t, first, secretsManager.ContentHash())
+}
+
+func TestSecretsManager_Get_ExpiredCredentials(t *testing.T) {
+	smMock := &mockSequencedSecretsManagerClient{responses: []mockSecretResponse{
+		{err: &smithy.GenericAPIError{Code: "ExpiredTokenException", Message: "The security token included in the request is expired"}},
+		{value: `{"DB_PASSWORD":"password"}`, version: "v1"},
+	}}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// The expired token is not retried with backoff; the clients are rebuilt and the call is tried once more.
+	start := time.Now()
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestSecretsManager_ResetIdentity(t *testing.T) {
+	secretJSON, err := json.Marshal(map[string]string{"DB_PASSWORD": "password"})
+	require.NoError(t, err)
+
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(string(secretJSON))
+	kmsMock := &mockKMSClient{}
+
+	secretsManager := newSecretsManagerForTest(t, smMock, kmsMock, time.Minute)
+
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+
+	// Resetting the identity purges the cache, so the next Get fetches again
+	// through the injected client, which is kept.
+	require.NoError(t, secretsManager.ResetIdentity(context.Background()))
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&smMock.callCount))
 }
 
 func TestSecretsManager_Watch(t *testing.T) {
@@ -279,6 +853,133 @@ func TestSecretsManager_Watch(t *testing.T) {
 	}
 }
 
+func TestSecretsManager_Watch_InitialFetchFails(t *testing.T) {
+	smMock := &mockSequencedSecretsManagerClient{responses: []mockSecretResponse{
+		{err: fmt.Errorf("simulated AWS error")},
+		{value: `{"DB_PASSWORD":"initialPassword"}`},
+		{value: `{"DB_PASSWORD":"rotatedPassword"}`},
+	}}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithRetry(1, time.Millisecond, time.Millisecond),
+		secretsmanagerWrapper.WithCacheTTL(time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	errCh := make(chan error, 1)
+	callbackCh := make(chan string, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	secretsManager.Watch(ctx, "DB_PASSWORD", 10*time.Millisecond, func(newVal string) {
+		callbackCh <- newVal
+	}, secretsmanagerWrapper.WatchOnError(func(err error) {
+		errCh <- err
+	}))
+
+	// The failed initial fetch is reported, and the watcher keeps going.
+	select {
+	case err := <-errCh:
+		require.ErrorContains(t, err, "simulated AWS error")
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timeout waiting for watcher error")
+	}
+	select {
+	case newVal := <-callbackCh:
+		require.Equal(t, "rotatedPassword", newVal)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timeout waiting for watcher callback")
+	}
+}
+
+func TestSecretsManager_Watch_Handle(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"initialPassword"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Millisecond)
+
+	watcher := secretsManager.Watch(context.Background(), "DB_PASSWORD", 5*time.Millisecond, func(string) {})
+	require.Eventually(t, func() bool {
+		return watcher.LastValue() == "initialPassword"
+	}, time.Second, 5*time.Millisecond)
+	require.True(t, watcher.IsRunning())
+	require.NoError(t, watcher.LastError())
+	require.WithinDuration(t, time.Now(), watcher.LastCheckedAt(), time.Second)
+
+	// A missing key is reported as the last error.
+	smMock.secretValue.Store(`{"OTHER":"value"}`)
+	require.Eventually(t, func() bool {
+		return errors.Is(watcher.LastError(), secretsmanagerWrapper.ErrSecretNotFound)
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, "initialPassword", watcher.LastValue())
+
+	watcher.Stop()
+	require.Eventually(t, func() bool {
+		return !watcher.IsRunning()
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSecretsManager_Watch_InvalidInterval(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"initialPassword"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// A non-positive interval is reported instead of panicking in the watcher's goroutine.
+	var reported error
+	watcher := secretsManager.Watch(context.Background(), "DB_PASSWORD", 0, func(string) {},
+		secretsmanagerWrapper.WatchOnError(func(err error) { reported = err }))
+	require.False(t, watcher.IsRunning())
+	require.ErrorIs(t, watcher.LastError(), secretsmanagerWrapper.ErrInvalidConfig)
+	require.ErrorIs(t, reported, secretsmanagerWrapper.ErrInvalidConfig)
+}
+
+// mockKMSClientPartialFailure simulates a KMS client that fails to encrypt plaintexts containing failOn.
+type mockKMSClientPartialFailure struct {
+	mockKMSClient
+	failOn string
+	// failed receives a value on every failure.
+	failed chan struct{}
+}
+
+func (m *mockKMSClientPartialFailure) Encrypt(ctx context.Context, input *kms.EncryptInput, opts ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	if strings.Contains(string(input.Plaintext), m.failOn) {
+		select {
+		case m.failed <- struct{}{}:
+		default:
+		}
+		return nil, fmt.Errorf("simulated encryption error")
+	}
+	return m.mockKMSClient.Encrypt(ctx, input, opts...)
+}
+
+func TestSecretsManager_Refresh_PartialEncryptionFailure(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"A":"a1","B":"b1","C":"c1"}`)
+	kmsMock := &mockKMSClientPartialFailure{failOn: "b2", failed: make(chan struct{}, 1)}
+	secretsManager := newSecretsManagerForTest(t, smMock, kmsMock, time.Hour)
+	_, err := secretsManager.Get("A")
+	require.NoError(t, err)
+	version := secretsManager.Version()
+
+	// Encrypting B fails, after A or C may already have been encrypted.
+	smMock.secretValue.Store(`{"A":"a2","B":"b2","C":"c2"}`)
+	ctx, cancel := context.WithCancel(context.Background())
+	secretsManager.StartAutoRefresh(ctx, 5*time.Millisecond)
+	select {
+	case <-kmsMock.failed:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("timeout waiting for refresh")
+	}
+	cancel()
+
+	// The cache still holds the previous generation only.
+	for key, want := range map[string]string{"A": "a1", "B": "b1", "C": "c1"} {
+		val, err := secretsManager.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, want, val)
+	}
+	require.Equal(t, version, secretsManager.Version())
+}
+
 func TestDecryptValue_InvalidBase64(t *testing.T) {
 	ctx := context.Background()
 
@@ -291,3 +992,18 @@ func TestDecryptValue_InvalidBase64(t *testing.T) {
 	require.Error(t, err)
 	require.Empty(t, plaintext)
 }
+
+func TestSecretsManager_Refresh_DeduplicatesValues(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"A":"shared","B":"shared","C":"shared","D":"other"}`)
+	kmsMock := &mockKMSDataKeyClient{}
+	secretsManager := newSecretsManagerForTest(t, smMock, kmsMock, time.Hour)
+
+	for _, key := range []string{"A", "B", "C"} {
+		val, err := secretsManager.Get(key)
+		require.NoError(t, err)
+		require.Equal(t, "shared", val)
+	}
+	// The two distinct values and the payload are encrypted once each.
+	require.Equal(t, int32(3), atomic.LoadInt32(&kmsMock.encryptCount))
+}
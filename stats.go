@@ -0,0 +1,43 @@
+package secretsmanager
+
+import "time"
+
+// CacheStats is a snapshot of the cache of a SecretsManager, see CacheStats. It holds key names
+// and ages only, never values.
+type CacheStats struct {
+	// Hits and Misses count the reads of keys that were served from the cache, and those that
+	// had to fetch the secret because the key was missing or expired.
+	Hits   uint64
+	Misses uint64
+	// Entries is the number of cached keys.
+	Entries int
+	// Version is the VersionId of the cached secret.
+	Version string
+	// OldestFetchedAt is when the least recently fetched entry was fetched, or the zero time if
+	// the cache is empty.
+	OldestFetchedAt time.Time
+	// KeyAges holds the time since each cached key was fetched.
+	KeyAges map[string]time.Duration
+}
+
+// CacheStats returns the hit and miss counts and the contents of the cache, e.g. to debug why a
+// service still sees an old password. Unlike Keys, it never fetches the secret.
+func (s *SecretsManager) CacheStats() CacheStats {
+	now := time.Now()
+	stats := CacheStats{
+		Hits:   s.cacheHits.Load(),
+		Misses: s.cacheMisses.Load(),
+	}
+	s.cacheLock.RLock()
+	defer s.cacheLock.RUnlock()
+	stats.Entries = len(s.cache)
+	stats.Version = s.versionID
+	stats.KeyAges = make(map[string]time.Duration, len(s.cache))
+	for key, cs := range s.cache {
+		stats.KeyAges[key] = now.Sub(cs.fetchedAt)
+		if stats.OldestFetchedAt.IsZero() || cs.fetchedAt.Before(stats.OldestFetchedAt) {
+			stats.OldestFetchedAt = cs.fetchedAt
+		}
+	}
+	return stats
+}
@@ -0,0 +1,40 @@
+package secretsmanager_test
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_CacheStats(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_USER":"user","DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	stats := secretsManager.CacheStats()
+	require.Zero(t, stats.Entries)
+	require.True(t, stats.OldestFetchedAt.IsZero())
+
+	before := time.Now()
+	_, err := secretsManager.Get("DB_USER")
+	require.NoError(t, err)
+	_, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	_, err = secretsManager.Get("DB_USER")
+	require.NoError(t, err)
+
+	stats = secretsManager.CacheStats()
+	require.Equal(t, uint64(2), stats.Hits)
+	require.Equal(t, uint64(1), stats.Misses)
+	require.Equal(t, 2, stats.Entries)
+	require.Equal(t, secretsManager.Version(), stats.Version)
+	require.False(t, stats.OldestFetchedAt.Before(before))
+	require.ElementsMatch(t, []string{"DB_USER", "DB_PASSWORD"}, slices.Collect(maps.Keys(stats.KeyAges)))
+	for _, age := range stats.KeyAges {
+		require.GreaterOrEqual(t, age, time.Duration(0))
+		require.Less(t, age, time.Since(before)+time.Millisecond)
+	}
+}
@@ -0,0 +1,97 @@
+package secretsmanager
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// SecretRequest selects the version of a secret to read from a SecretStore.
+type SecretRequest struct {
+	// SecretID names the secret.
+	SecretID string
+	// VersionID selects a version by its ID, and VersionStage by its staging label, such as
+	// VersionStageCurrent. Both are optional.
+	VersionID    string
+	VersionStage string
+}
+
+// StoredSecret is a secret version as read from a SecretStore. Either SecretString or
+// SecretBinary is set.
+type StoredSecret struct {
+	SecretString string
+	SecretBinary []byte
+	// VersionID identifies the version; it changes whenever the value changes.
+	VersionID string
+}
+
+// SecretStore is where a SecretsManager reads its secret from. SecretsManagerStore reads from AWS
+// Secrets Manager; the paramstore subpackage provides one for AWS Systems Manager Parameter Store.
+// The cache, watchers and cache encryption work the same for every store. Features built on other
+// Secrets Manager operations, such as WithRotationAwareTTL, are only available with
+// SecretsManagerStore.
+type SecretStore interface {
+	ReadSecret(ctx context.Context, req SecretRequest) (*StoredSecret, error)
+}
+
+// SecretsManagerStore returns the SecretStore that reads secrets from AWS Secrets Manager through
+// client, such as the default client.
+func SecretsManagerStore(client Client) SecretStore {
+	return secretsManagerStore{client: client}
+}
+
+// secretsManagerStore is the SecretStore of AWS Secrets Manager.
+type secretsManagerStore struct {
+	client Client
+}
+
+// ReadSecret implements SecretStore.
+func (s secretsManagerStore) ReadSecret(ctx context.Context, req SecretRequest) (*StoredSecret, error) {
+	out, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     &req.SecretID,
+		VersionId:    nonEmpty(req.VersionID),
+		VersionStage: nonEmpty(req.VersionStage),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &StoredSecret{
+		SecretString: aws.ToString(out.SecretString),
+		SecretBinary: out.SecretBinary,
+		VersionID:    aws.ToString(out.VersionId),
+	}, nil
+}
+
+// storeClient reads through a SecretStore as a Secrets Manager client, so the fetch path is the
+// same for every store.
+type storeClient struct {
+	store SecretStore
+}
+
+// GetSecretValue implements Client.
+func (c storeClient) GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput, _ ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	secret, err := c.store.ReadSecret(ctx, SecretRequest{
+		SecretID:     aws.ToString(input.SecretId),
+		VersionID:    aws.ToString(input.VersionId),
+		VersionStage: aws.ToString(input.VersionStage),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &secretsmanager.GetSecretValueOutput{
+		Name:         input.SecretId,
+		SecretString: nonEmpty(secret.SecretString),
+		SecretBinary: secret.SecretBinary,
+		VersionId:    nonEmpty(secret.VersionID),
+	}, nil
+}
+
+// WithSecretStore reads the secret from store instead of AWS Secrets Manager.
+func WithSecretStore(store SecretStore) Option {
+	if sm, ok := store.(secretsManagerStore); ok {
+		// Keep the client itself, so the features built on other operations still work.
+		return WithSecretsManagerClient(sm.client)
+	}
+	return WithSecretsManagerClient(storeClient{store: store})
+}
@@ -0,0 +1,54 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mapStore is a SecretStore that serves secrets from memory.
+type mapStore struct {
+	secrets  map[string]string
+	requests []secretsmanagerWrapper.SecretRequest
+}
+
+func (m *mapStore) ReadSecret(_ context.Context, req secretsmanagerWrapper.SecretRequest) (*secretsmanagerWrapper.StoredSecret, error) {
+	m.requests = append(m.requests, req)
+	return &secretsmanagerWrapper.StoredSecret{SecretString: m.secrets[req.SecretID], VersionID: "1"}, nil
+}
+
+func TestSecretsManager_WithSecretStore(t *testing.T) {
+	store := &mapStore{secrets: map[string]string{"test-secret": `{"DB_PASSWORD":"password"}`}}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretStore(store),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+	)
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+	require.Equal(t, "1", secretsManager.Version())
+	require.Equal(t, []secretsmanagerWrapper.SecretRequest{{SecretID: "test-secret"}}, store.requests)
+}
+
+func TestSecretsManager_WithSecretStore_SecretsManager(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretStore(secretsmanagerWrapper.SecretsManagerStore(smMock)),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+	)
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "password", val)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
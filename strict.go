@@ -0,0 +1,120 @@
+package secretsmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrInvalidSecretJSON is returned by strict parsing for secret payloads that the standard JSON
+// decoder would silently accept, see WithStrictJSON.
+var ErrInvalidSecretJSON = errors.New("invalid secret JSON")
+
+// WithStrictJSON rejects secret payloads that contain duplicate keys, invalid UTF-8 or escapes
+// of unpaired UTF-16 surrogates. The standard decoder keeps the last of duplicate keys and
+// replaces invalid characters with U+FFFD, which can silently serve a wrong value.
+func WithStrictJSON() Option {
+	return func(s *SecretsManager) {
+		s.strictJSON = true
+	}
+}
+
+// checkPayload runs the strict checks on a JSON payload if WithStrictJSON is set.
+func (s *SecretsManager) checkPayload(data []byte) error {
+	if !s.strictJSON {
+		return nil
+	}
+	if !utf8.Valid(data) {
+		return fmt.Errorf("%w: not valid UTF-8", ErrInvalidSecretJSON)
+	}
+	if err := checkEscapes(data); err != nil {
+		return err
+	}
+	return checkDuplicateKeys(json.NewDecoder(bytes.NewReader(data)), "")
+}
+
+// checkEscapes reports \u escapes of UTF-16 surrogates that are not part of a valid pair.
+// Backslashes only occur in JSON strings, so data need not be tokenized.
+func checkEscapes(data []byte) error {
+	for i := 0; i < len(data); i++ {
+		if data[i] != '\\' {
+			continue
+		}
+		i++
+		if i >= len(data) || data[i] != 'u' {
+			// Any other escape, including an escaped backslash, is a single character.
+			continue
+		}
+		r, ok := hexRune(data[i+1:])
+		if !ok || !utf16.IsSurrogate(r) {
+			continue
+		}
+		i += 4
+		next, ok := hexRune(data[min(i+3, len(data)):])
+		if r >= 0xdc00 || !bytes.HasPrefix(data[i+1:], []byte(`\u`)) || !ok ||
+			utf16.DecodeRune(r, next) == utf8.RuneError {
+			return fmt.Errorf("%w: unpaired surrogate escape \\u%04x", ErrInvalidSecretJSON, r)
+		}
+		i += 6
+	}
+	return nil
+}
+
+// hexRune decodes the four hex digits at the start of data.
+func hexRune(data []byte) (rune, bool) {
+	if len(data) < 4 {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(string(data[:4]), 16, 32)
+	return rune(n), err == nil
+}
+
+// checkDuplicateKeys reads the next JSON value from dec and reports object keys that occur more
+// than once, naming them by their path.
+func checkDuplicateKeys(dec *json.Decoder, path string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		seen := make(map[string]bool)
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := joinPath(path, tok.(string))
+			if seen[key] {
+				return fmt.Errorf("%w: duplicate key %s", ErrInvalidSecretJSON, key)
+			}
+			seen[key] = true
+			if err := checkDuplicateKeys(dec, key); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := checkDuplicateKeys(dec, joinPath(path, strconv.Itoa(i))); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	// Consume the closing delimiter.
+	_, err = dec.Token()
+	return err
+}
+
+// joinPath appends elem to the dotted path of a JSON value.
+func joinPath(path, elem string) string {
+	if path == "" {
+		return elem
+	}
+	return path + "." + elem
+}
@@ -0,0 +1,56 @@
+package secretsmanager_test
+
+import (
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_WithStrictJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		wantErr string
+	}{
+		{name: "valid", payload: `{"DB_PASSWORD":"päss😀","DB_USER":"admin\\u"}`},
+		{name: "duplicate key", payload: `{"DB_PASSWORD":"first","DB_PASSWORD":"second"}`, wantErr: "duplicate key DB_PASSWORD"},
+		{name: "duplicate key in array element", payload: `[{"name":"a"},{"name":"b","name":"c"}]`, wantErr: "duplicate key 1.name"},
+		{name: "lone high surrogate", payload: `{"DB_PASSWORD":"\ud83dx"}`, wantErr: "unpaired surrogate"},
+		{name: "lone low surrogate", payload: `{"DB_PASSWORD":"\ude00"}`, wantErr: "unpaired surrogate"},
+		{name: "invalid UTF-8", payload: "{\"DB_PASSWORD\":\"\xff\"}", wantErr: "not valid UTF-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			smMock := &mockSecretsManagerClient{}
+			smMock.secretValue.Store(tt.payload)
+			secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+				secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+				secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+				secretsmanagerWrapper.WithRetry(1, time.Millisecond, time.Millisecond),
+				secretsmanagerWrapper.WithStrictJSON(),
+			)
+			require.NoError(t, err)
+
+			_, err = secretsManager.Get("DB_PASSWORD")
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, secretsmanagerWrapper.ErrInvalidSecretJSON)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestSecretsManager_DuplicateKeysWithoutStrictJSON(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"first","DB_PASSWORD":"second"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// Without strict parsing, the last duplicate wins, as with json.Unmarshal.
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "second", val)
+}
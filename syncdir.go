@@ -0,0 +1,197 @@
+package secretsmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// syncOptions holds the settings of SyncToDir.
+type syncOptions struct {
+	mode     os.FileMode
+	onChange func(changed []string) error
+	pid      int
+	signal   os.Signal
+}
+
+// SyncOption defines a functional option for configuring SyncToDir.
+type SyncOption func(*syncOptions)
+
+// SyncFileMode sets the permissions of the files, 0o600 by default.
+func SyncFileMode(mode os.FileMode) SyncOption {
+	return func(o *syncOptions) {
+		o.mode = mode
+	}
+}
+
+// SyncOnChange calls onChange with the keys whose files were rewritten or removed after the
+// secret changed, for example to reload the software that reads them.
+func SyncOnChange(onChange func(changed []string) error) SyncOption {
+	return func(o *syncOptions) {
+		o.onChange = onChange
+	}
+}
+
+// SyncSignal sends sig, such as syscall.SIGHUP, to the process with the given pid after the
+// files changed, so that software like nginx or Postgres reloads them.
+func SyncSignal(pid int, sig os.Signal) SyncOption {
+	return func(o *syncOptions) {
+		o.pid = pid
+		o.signal = sig
+	}
+}
+
+// SyncedDir is a directory holding a file per secret key, see SyncToDir.
+type SyncedDir struct {
+	dir     string
+	opts    syncOptions
+	watcher *Watcher
+
+	// mu serializes renders with Close.
+	mu     sync.Mutex
+	values map[string]string
+	closed bool
+}
+
+// SyncToDir writes the value of every key of the secret to a file named after the key in dir,
+// for sidecar-style consumers that read credentials from disk. Files are replaced atomically, so
+// readers never see a partial value. The secret is checked every cache TTL until ctx is done or
+// Close is called; after a rotation, changed files are rewritten, files of removed keys are
+// deleted, and the hooks set by opts are run. Keys that are not valid file names, such as those
+// containing a path separator, are rejected. Call Close to overwrite the files with zeros and
+// remove them. To write a single key, use MaterializeToFile.
+func (s *SecretsManager) SyncToDir(ctx context.Context, dir string, opts ...SyncOption) (*SyncedDir, error) {
+	d := &SyncedDir{dir: dir, opts: syncOptions{mode: 0o600}}
+	for _, opt := range opts {
+		opt(&d.opts)
+	}
+
+	if err := s.ensureFresh(ctx); err != nil {
+		return nil, err
+	}
+	// Read the hash first, so a concurrent refresh is picked up by the next check.
+	lastHash := s.ContentHash()
+	values, err := s.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.render(values); err != nil {
+		return nil, err
+	}
+
+	d.watcher = s.startWatcher(ctx, s.cacheTTL, nil, func(ctx context.Context) (string, error) {
+		if err := s.ensureFresh(ctx); err != nil {
+			return "", err
+		}
+		hash := s.ContentHash()
+		if hash == lastHash {
+			return "", nil
+		}
+		values, err := s.GetAll(ctx)
+		if err != nil {
+			return "", err
+		}
+		lastHash = hash
+		if err := d.sync(values); err != nil {
+			s.log().Error(ctx, "failed to sync secret to directory", "secret", s.secretName, "dir", dir, "error", err)
+			return "", err
+		}
+		return "", nil
+	})
+	return d, nil
+}
+
+// Dir returns the directory the files are written to.
+func (d *SyncedDir) Dir() string {
+	return d.dir
+}
+
+// sync renders values and runs the hooks if any file changed.
+func (d *SyncedDir) sync(values map[string]string) error {
+	changed, err := d.render(values)
+	if len(changed) == 0 {
+		return err
+	}
+	if d.opts.onChange != nil {
+		err = errors.Join(err, d.opts.onChange(changed))
+	}
+	if d.opts.signal != nil {
+		p, findErr := os.FindProcess(d.opts.pid)
+		if findErr == nil {
+			findErr = p.Signal(d.opts.signal)
+		}
+		err = errors.Join(err, findErr)
+	}
+	return err
+}
+
+// render writes the files of the keys whose values differ from the previous render, removes the
+// files of keys that are gone, and returns the changed keys in sorted order.
+func (d *SyncedDir) render(values map[string]string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil, nil
+	}
+	for key := range values {
+		if !filepath.IsLocal(key) || strings.ContainsAny(key, `/\`) {
+			return nil, fmt.Errorf("key %q is not a valid file name", key)
+		}
+	}
+
+	var changed []string
+	var errs []error
+	written := maps.Clone(values)
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		old, ok := d.values[key]
+		if ok && old == values[key] {
+			continue
+		}
+		if err := writeFileAtomic(filepath.Join(d.dir, key), d.opts.mode, values[key]); err != nil {
+			// Keep track of what is on disk, so the key is written again on the next change.
+			if ok {
+				written[key] = old
+			} else {
+				delete(written, key)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		changed = append(changed, key)
+	}
+	for _, key := range slices.Sorted(maps.Keys(d.values)) {
+		if _, ok := values[key]; !ok {
+			if err := removeShredded(filepath.Join(d.dir, key)); err != nil {
+				errs = append(errs, err)
+			}
+			changed = append(changed, key)
+		}
+	}
+	d.values = written
+	return changed, errors.Join(errs...)
+}
+
+// Close stops syncing, and overwrites the files with zeros and removes them. As with
+// MaterializedFile.Close, overwriting is best effort.
+func (d *SyncedDir) Close() error {
+	if d.watcher != nil {
+		d.watcher.Stop()
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil
+	}
+	d.closed = true
+	var errs []error
+	for key := range d.values {
+		errs = append(errs, removeShredded(filepath.Join(d.dir, key)))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,68 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_SyncToDir(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"tls.crt":"initialCert","tls.key":"initialKey"}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(20*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	changes := make(chan []string, 1)
+	d, err := secretsManager.SyncToDir(context.Background(), dir, secretsmanagerWrapper.SyncOnChange(func(changed []string) error {
+		changes <- changed
+		return nil
+	}))
+	require.NoError(t, err)
+	require.Equal(t, dir, d.Dir())
+
+	data, err := os.ReadFile(filepath.Join(dir, "tls.key"))
+	require.NoError(t, err)
+	require.Equal(t, "initialKey", string(data))
+	info, err := os.Stat(filepath.Join(dir, "tls.crt"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// After a rotation, changed files are rewritten and files of removed keys are deleted.
+	smMock.secretValue.Store(`{"tls.key":"rotatedKey"}`)
+	select {
+	case changed := <-changes:
+		require.Equal(t, []string{"tls.key", "tls.crt"}, changed)
+	case <-time.After(time.Second):
+		t.Fatal("files were not synced after the rotation")
+	}
+	data, err = os.ReadFile(filepath.Join(dir, "tls.key"))
+	require.NoError(t, err)
+	require.Equal(t, "rotatedKey", string(data))
+	_, err = os.Stat(filepath.Join(dir, "tls.crt"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// Close removes the files.
+	require.NoError(t, d.Close())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestSecretsManager_SyncToDir_InvalidKey(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"../escape":"value"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	_, err := secretsManager.SyncToDir(context.Background(), t.TempDir())
+	require.ErrorContains(t, err, "not a valid file name")
+}
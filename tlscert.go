@@ -0,0 +1,158 @@
+package secretsmanager
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// GetTLSCertificate parses the PEM-encoded certificate chain and private key stored under certKey
+// and keyKey, as tls.X509KeyPair does. Both keys may be the same, for a value holding both.
+func (s *SecretsManager) GetTLSCertificate(ctx context.Context, certKey, keyKey string) (*tls.Certificate, error) {
+	certPEM, err := s.GetWithContext(ctx, certKey)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := s.GetWithContext(ctx, keyKey)
+	if err != nil {
+		return nil, err
+	}
+	return parseCertificate(certKey, keyKey, certPEM, keyPEM)
+}
+
+// parseCertificate parses the PEM-encoded certificate chain and private key read from certKey and
+// keyKey.
+func parseCertificate(certKey, keyKey, certPEM, keyPEM string) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse TLS certificate from keys %q and %q: %w", certKey, keyKey, err)
+	}
+	return &cert, nil
+}
+
+// CertificateReloader serves a TLS certificate from a secret and reloads it after rotations, see
+// WatchTLSCertificate. It is safe for concurrent use.
+type CertificateReloader struct {
+	cert    atomic.Pointer[tls.Certificate]
+	watcher *Watcher
+}
+
+// WatchTLSCertificate loads the TLS certificate stored under certKey and keyKey, as
+// GetTLSCertificate does, and watches both keys every cache TTL until ctx is done or Stop is
+// called. After a rotation, the certificate is reloaded; if the new one fails to parse, the error
+// is logged and reported as set by opts, and the previous certificate is kept. Set the
+// GetCertificate method of the returned reloader as tls.Config.GetCertificate, so servers pick up
+// new certificates for new connections without a restart.
+func (s *SecretsManager) WatchTLSCertificate(ctx context.Context, certKey, keyKey string, opts ...WatchOption) (*CertificateReloader, error) {
+	cert, err := s.GetTLSCertificate(ctx, certKey, keyKey)
+	if err != nil {
+		return nil, err
+	}
+	r := &CertificateReloader{}
+	r.cert.Store(cert)
+
+	var o watchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	r.watcher = s.WatchKeys(ctx, []string{certKey, keyKey}, s.cacheTTL, func(map[string]string) {
+		cert, err := s.GetTLSCertificate(ctx, certKey, keyKey)
+		if err != nil {
+			s.log().Error(ctx, "failed to reload TLS certificate", "secret", s.secretName, "error", err)
+			if o.onError != nil {
+				o.onError(err)
+			}
+			return
+		}
+		r.cert.Store(cert)
+	}, opts...)
+	return r, nil
+}
+
+// GetCertificate returns the current certificate. It has the signature of
+// tls.Config.GetCertificate.
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return r.cert.Load(), nil
+}
+
+// Stop stops watching the secret. The current certificate is still served.
+func (r *CertificateReloader) Stop() {
+	r.watcher.Stop()
+}
+
+// GetCertificateFunc returns a function for tls.Config.GetCertificate that serves the TLS
+// certificate stored under certKey and keyKey from the cache. Unlike WatchTLSCertificate, it
+// starts no goroutine: a rotation is picked up by the first handshake after the cache expires.
+// Handshakes decrypt nothing while the content of the secret is unchanged, see ContentHash; the
+// certificate is only read and parsed again after it changed. If the secret cannot be read, or a
+// new certificate fails to parse, the error is logged and the previous certificate is served;
+// handshakes only fail while no certificate was loaded yet.
+func (s *SecretsManager) GetCertificateFunc(certKey, keyKey string) func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c := &certificateCache{s: s, certKey: certKey, keyKey: keyKey}
+	return c.getCertificate
+}
+
+// certificateCache holds the certificate served by GetCertificateFunc, and the content hash of
+// the secret it was read from.
+type certificateCache struct {
+	s               *SecretsManager
+	certKey, keyKey string
+
+	mu   sync.Mutex
+	hash string
+	cert *tls.Certificate
+	// badHash is the content hash of the last secret whose certificate failed to parse, which is
+	// not parsed again.
+	badHash string
+}
+
+// getCertificate returns the certificate, reading and parsing it again if the secret changed.
+func (c *certificateCache) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	ctx := context.Background()
+	if hello != nil && hello.Context() != nil {
+		ctx = hello.Context()
+	}
+	err := c.s.checkAccess(ctx, c.certKey)
+	if err == nil {
+		err = c.s.checkAccess(ctx, c.keyKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Refresh the secret if it expired, without decrypting anything.
+	err = c.s.ensureFresh(ctx)
+	hash := c.s.ContentHash()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil && c.cert != nil && (hash == c.hash || hash == c.badHash) {
+		return c.cert, nil
+	}
+	var certPEM, keyPEM string
+	if err == nil {
+		certPEM, err = c.s.lookup(ctx, c.certKey)
+	}
+	if err == nil {
+		keyPEM, err = c.s.lookup(ctx, c.keyKey)
+	}
+	if err != nil {
+		if c.cert == nil {
+			return nil, err
+		}
+		c.s.log().Error(ctx, "failed to read TLS certificate; serving the previous one", "secret", c.s.secretName, "error", err)
+		return c.cert, nil
+	}
+	cert, err := parseCertificate(c.certKey, c.keyKey, certPEM, keyPEM)
+	if err != nil {
+		if c.cert == nil {
+			return nil, err
+		}
+		c.s.log().Error(ctx, "failed to reload TLS certificate; serving the previous one", "secret", c.s.secretName, "error", err)
+		c.badHash = hash
+		return c.cert, nil
+	}
+	c.hash, c.cert = hash, cert
+	return cert, nil
+}
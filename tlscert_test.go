@@ -0,0 +1,143 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// generateCertificate returns a self-signed PEM certificate and private key for commonName.
+func generateCertificate(t *testing.T, commonName string) (certPEM, keyPEM string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+// storeCertificate stores a new certificate for commonName in the mock secret.
+func storeCertificate(t *testing.T, smMock *mockSecretsManagerClient, commonName string) {
+	t.Helper()
+	certPEM, keyPEM := generateCertificate(t, commonName)
+	secretJSON, err := json.Marshal(map[string]string{"TLS_CERT": certPEM, "TLS_KEY": keyPEM})
+	require.NoError(t, err)
+	smMock.secretValue.Store(string(secretJSON))
+}
+
+func TestSecretsManager_GetTLSCertificate(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	storeCertificate(t, smMock, "initial.example.com")
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	cert, err := secretsManager.GetTLSCertificate(context.Background(), "TLS_CERT", "TLS_KEY")
+	require.NoError(t, err)
+	require.Equal(t, "initial.example.com", cert.Leaf.Subject.CommonName)
+
+	// The certificate and key must match.
+	_, err = secretsManager.GetTLSCertificate(context.Background(), "TLS_CERT", "TLS_CERT")
+	require.ErrorContains(t, err, "failed to parse TLS certificate")
+}
+
+func TestSecretsManager_WatchTLSCertificate(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	storeCertificate(t, smMock, "initial.example.com")
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, 20*time.Millisecond)
+
+	r, err := secretsManager.WatchTLSCertificate(context.Background(), "TLS_CERT", "TLS_KEY")
+	require.NoError(t, err)
+	defer r.Stop()
+	cert, err := r.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "initial.example.com", cert.Leaf.Subject.CommonName)
+
+	// The rotated certificate is served without a restart.
+	storeCertificate(t, smMock, "rotated.example.com")
+	require.Eventually(t, func() bool {
+		cert, err := r.GetCertificate(nil)
+		return err == nil && cert.Leaf.Subject.CommonName == "rotated.example.com"
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSecretsManager_GetCertificateFunc(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	storeCertificate(t, smMock, "initial.example.com")
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, 20*time.Millisecond)
+
+	getCertificate := secretsManager.GetCertificateFunc("TLS_CERT", "TLS_KEY")
+	cert, err := getCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Equal(t, "initial.example.com", cert.Leaf.Subject.CommonName)
+
+	// An unchanged certificate is not parsed again.
+	time.Sleep(30 * time.Millisecond)
+	again, err := getCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Same(t, cert, again)
+
+	// The rotated certificate is served once the cache expired.
+	storeCertificate(t, smMock, "rotated.example.com")
+	time.Sleep(30 * time.Millisecond)
+	cert, err = getCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Equal(t, "rotated.example.com", cert.Leaf.Subject.CommonName)
+
+	// A broken rotation keeps the previous certificate.
+	smMock.secretValue.Store(`{"TLS_CERT":"broken","TLS_KEY":"broken"}`)
+	time.Sleep(30 * time.Millisecond)
+	again, err = getCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	require.Same(t, cert, again)
+}
+
+func TestSecretsManager_GetCertificateFunc_NoDecryptPerHandshake(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	storeCertificate(t, smMock, "initial.example.com")
+	kmsMock := &mockKMSClientCountingDecrypt{}
+	secretsManager := newSecretsManagerForTest(t, smMock, kmsMock, time.Hour)
+
+	getCertificate := secretsManager.GetCertificateFunc("TLS_CERT", "TLS_KEY")
+	cert, err := getCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	decrypts := atomic.LoadInt32(&kmsMock.decryptCount)
+	require.NotZero(t, decrypts)
+
+	// Handshakes against the unchanged secret neither decrypt nor parse anything.
+	for range 5 {
+		again, err := getCertificate(&tls.ClientHelloInfo{})
+		require.NoError(t, err)
+		require.Same(t, cert, again)
+	}
+	require.Equal(t, decrypts, atomic.LoadInt32(&kmsMock.decryptCount))
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
+
+func TestSecretsManager_GetCertificateFunc_NoCertificate(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"TLS_CERT":"broken","TLS_KEY":"broken"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+
+	_, err := secretsManager.GetCertificateFunc("TLS_CERT", "TLS_KEY")(nil)
+	require.ErrorContains(t, err, "failed to parse TLS certificate")
+}
@@ -0,0 +1,54 @@
+package secretsmanager
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracerName is the instrumentation scope of the spans created by this package.
+const tracerName = "github.com/janduursma/aws-secretsmanager-wrapper-go"
+
+// Span attribute keys.
+const (
+	attrSecretName = attribute.Key("secretsmanager.secret_name")
+	attrKey        = attribute.Key("secretsmanager.key")
+	attrCacheHit   = attribute.Key("secretsmanager.cache_hit")
+	attrEndUserID  = attribute.Key("enduser.id")
+)
+
+// attrMetadataPrefix prefixes the span attribute keys of call metadata.
+const attrMetadataPrefix = "secretsmanager.metadata."
+
+// noopSpan is returned by startSpan if tracing is disabled. It is boxed once, so
+// that untraced calls do not allocate.
+var noopSpan trace.Span = noop.Span{}
+
+// WithTracerProvider enables OpenTelemetry tracing. Spans are created for reads, with whether
+// they were served from the cache, for fetches from AWS Secrets Manager and for KMS Encrypt and
+// Decrypt calls, as children of the span in the caller's context. Values are never recorded.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(s *SecretsManager) {
+		s.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// startSpan starts a span with the given name as a child of the span in ctx.
+func (s *SecretsManager) startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if s.tracer == nil {
+		return ctx, noopSpan
+	}
+	return s.tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,67 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// recordingTracerProvider records the names of the spans that are started.
+type recordingTracerProvider struct {
+	noop.TracerProvider
+	mu    sync.Mutex
+	spans []string
+}
+
+func (p *recordingTracerProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return recordingTracer{provider: p}
+}
+
+// names returns the names of the spans started so far.
+func (p *recordingTracerProvider) names() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.spans...)
+}
+
+// recordingTracer records the spans it starts with its provider.
+type recordingTracer struct {
+	noop.Tracer
+	provider *recordingTracerProvider
+}
+
+func (t recordingTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.provider.mu.Lock()
+	t.provider.spans = append(t.provider.spans, name)
+	t.provider.mu.Unlock()
+	return t.Tracer.Start(ctx, name, opts...)
+}
+
+func TestSecretsManager_WithTracerProvider(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	tp := &recordingTracerProvider{}
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithCacheTTL(time.Minute),
+		secretsmanagerWrapper.WithTracerProvider(tp),
+	)
+	require.NoError(t, err)
+
+	// A cache miss fetches and encrypts the secret, then decrypts the value.
+	_, err = secretsManager.GetWithContext(context.Background(), "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, []string{"secretsmanager.Get", "secretsmanager.FetchSecret", "kms.Encrypt", "kms.Encrypt", "kms.Decrypt"}, tp.names())
+
+	// A cache hit only decrypts.
+	_, err = secretsManager.GetWithContext(context.Background(), "DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, []string{"secretsmanager.Get", "kms.Decrypt"}, tp.names()[5:])
+}
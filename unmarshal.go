@@ -0,0 +1,260 @@
+package secretsmanager
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// GetInto decodes the JSON payload of the secret into target, which must be a non-nil pointer,
+// e.g. to a struct with json tags. Unlike Get, nested objects and non-string values are decoded
+// into their Go types, so no manual conversion is needed. Binary secrets holding JSON are decoded
+// as well if WithBinarySecrets is set. References to other secrets are not resolved.
+// The secret is refreshed first if the cache is expired.
+func (s *SecretsManager) GetInto(ctx context.Context, target any) error {
+	if err := s.checkAccess(ctx, ""); err != nil {
+		return err
+	}
+	plaintext, err := s.decryptPayload(ctx)
+	if err != nil {
+		return err
+	}
+	if err := decodeWithTag([]byte(plaintext), target, s.structTag); err != nil {
+		return fmt.Errorf("failed to decode secret %q: %w", s.secretName, err)
+	}
+	return nil
+}
+
+// GetJSON decodes the JSON value for the given key into target, which must be a non-nil pointer,
+// e.g. for a key such as OAUTH_CONFIG that holds a nested object. The value may be stored as a
+// nested object or array in the secret, or as a string holding JSON. Keys are matched to struct
+// fields as with GetInto. A missing key is reported as ErrSecretNotFound. Like Get, it only
+// refreshes the secret if the cache is expired.
+func (s *SecretsManager) GetJSON(key string, target any) error {
+	ctx := context.Background()
+	if err := s.checkAccess(ctx, key); err != nil {
+		return err
+	}
+	val, err := s.jsonValue(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := decodeWithTag([]byte(val), target, s.structTag); err != nil {
+		return fmt.Errorf("key %q in secret %q is not valid JSON: %w", key, s.secretName, err)
+	}
+	return nil
+}
+
+// jsonValue returns the value for key as GetJSON decodes it: the JSON text of a nested object or
+// array, or else the value as Get returns it.
+func (s *SecretsManager) jsonValue(ctx context.Context, key string) (string, error) {
+	s.cacheLock.RLock()
+	_, cached := s.cache[key]
+	s.cacheLock.RUnlock()
+	if !cached {
+		// Nested objects and arrays are only cached flattened, so resolve the key in the cached
+		// payload, which is refreshed only if it is expired.
+		raw, ok, err := s.payloadValue(ctx, key)
+		if raw = bytes.TrimSpace(raw); err == nil && ok && (raw[0] == '{' || raw[0] == '[') {
+			return string(raw), nil
+		}
+	}
+	// Leaves, defaults and missing keys are read as with Get, from the cache while it is fresh.
+	return s.lookup(ctx, key)
+}
+
+// payloadValue returns the JSON value at the dotted path key in the payload of the secret.
+func (s *SecretsManager) payloadValue(ctx context.Context, key string) (json.RawMessage, bool, error) {
+	plaintext, err := s.decryptPayload(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	raw, ok := lookupPath(json.RawMessage(plaintext), key)
+	return raw, ok, nil
+}
+
+// lookupPath returns the JSON value at path within raw, where path joins object keys and array
+// indexes with dots as in the flattened cache keys. Object keys may contain dots themselves.
+func lookupPath(raw json.RawMessage, path string) (json.RawMessage, bool) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, false
+	}
+	var children map[string]json.RawMessage
+	switch raw[0] {
+	case '{':
+		if err := json.Unmarshal(raw, &children); err != nil {
+			return nil, false
+		}
+	case '[':
+		var elements []json.RawMessage
+		if err := json.Unmarshal(raw, &elements); err != nil {
+			return nil, false
+		}
+		children = make(map[string]json.RawMessage, len(elements))
+		for i, v := range elements {
+			children[strconv.Itoa(i)] = v
+		}
+	default:
+		return nil, false
+	}
+	if v, ok := children[path]; ok {
+		return v, true
+	}
+	for i := range len(path) {
+		if path[i] != '.' {
+			continue
+		}
+		if v, ok := children[path[:i]]; ok {
+			if found, ok := lookupPath(v, path[i+1:]); ok {
+				return found, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// decryptPayload returns the decrypted JSON payload of the secret, refreshing it first if the
+// cache is expired.
+func (s *SecretsManager) decryptPayload(ctx context.Context) (string, error) {
+	s.cacheLock.RLock()
+	cs := s.rawPayload()
+	s.cacheLock.RUnlock()
+	if cs.encryptedValue == "" || !s.isFresh(cs) {
+		if err := s.refresh(ctx); err != nil {
+			return "", err
+		}
+		s.cacheLock.RLock()
+		cs = s.rawPayload()
+		s.cacheLock.RUnlock()
+	}
+	if cs.encryptedValue == "" {
+		return "", fmt.Errorf("secret %q has no JSON payload", s.secretName)
+	}
+
+	plaintext, err := s.cacheCipher().Decrypt(ctx, cs.encryptedValue)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt cached payload: %w", err)
+	}
+	return plaintext, nil
+}
+
+// WithStructTag makes GetInto match object keys to struct fields by the given struct tag, such as
+// "mapstructure" for config structs shared with viper, instead of json tags. Fields without the
+// tag are matched by name, ignoring case. Embedded structs are flattened, as with json and with
+// the squash option of mapstructure.
+func WithStructTag(tagName string) Option {
+	return func(s *SecretsManager) {
+		s.structTag = tagName
+	}
+}
+
+// decodeWithTag decodes the JSON data into target like json.Unmarshal, but matches object keys to
+// struct fields by tagName. It renames the keys to the names json expects, and then decodes.
+func decodeWithTag(data []byte, target any, tagName string) error {
+	if tagName == "" || tagName == "json" {
+		return json.Unmarshal(data, target)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return err
+	}
+	renamed, err := json.Marshal(renameKeys(tree, reflect.TypeOf(target), tagName))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(renamed, target)
+}
+
+// renameKeys returns the decoded JSON value v with the object keys for struct fields of type t
+// renamed from their tagName names to their json names.
+func renameKeys(v any, t reflect.Type, tagName string) any {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return v
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if obj, ok := v.(map[string]any); ok {
+			out := make(map[string]any, len(obj))
+			renameFields(obj, out, t, tagName)
+			return out
+		}
+	case reflect.Slice, reflect.Array:
+		if arr, ok := v.([]any); ok {
+			for i := range arr {
+				arr[i] = renameKeys(arr[i], t.Elem(), tagName)
+			}
+		}
+	case reflect.Map:
+		if obj, ok := v.(map[string]any); ok {
+			for k, val := range obj {
+				obj[k] = renameKeys(val, t.Elem(), tagName)
+			}
+		}
+	}
+	return v
+}
+
+// renameFields stores the values of obj for the fields of struct type t under their json names in
+// out. Keys that match no field are left out, so json cannot match them to a field by its own,
+// case-insensitive rules.
+func renameFields(obj, out map[string]any, t reflect.Type, tagName string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get(tagName), ",")
+		if name == "-" {
+			continue
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			renameFields(obj, out, ft, tagName)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		key, ok := findKey(obj, cmp.Or(name, f.Name), name == "")
+		if !ok {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		out[cmp.Or(jsonName, f.Name)] = renameKeys(obj[key], f.Type, tagName)
+	}
+}
+
+// findKey returns the key of obj that equals name, or, if foldCase is set, matches it ignoring case.
+func findKey(obj map[string]any, name string, foldCase bool) (string, bool) {
+	if _, ok := obj[name]; ok {
+		return name, true
+	}
+	if foldCase {
+		for k := range obj {
+			if strings.EqualFold(k, name) {
+				return k, true
+			}
+		}
+	}
+	return "", false
+}
+
+// rawPayload returns the cached raw payload of a string secret, or else of a binary secret.
+// The caller must hold cacheLock.
+func (s *SecretsManager) rawPayload() cachedSecret {
+	if s.payload.encryptedValue != "" {
+		return s.payload
+	}
+	return s.binary
+}
@@ -0,0 +1,153 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_GetInto(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"host":"db.internal","port":5432,"tls":true,"credentials":{"user":"admin","password":"password"}}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	var config struct {
+		Host        string `json:"host"`
+		Port        int    `json:"port"`
+		TLS         bool   `json:"tls"`
+		Credentials struct {
+			User     string `json:"user"`
+			Password string `json:"password"`
+		} `json:"credentials"`
+	}
+	require.NoError(t, secretsManager.GetInto(context.Background(), &config))
+	require.Equal(t, "db.internal", config.Host)
+	require.Equal(t, 5432, config.Port)
+	require.True(t, config.TLS)
+	require.Equal(t, "password", config.Credentials.Password)
+
+	// Non-string values can still be read with Get, as their JSON text.
+	val, err := secretsManager.Get("port")
+	require.NoError(t, err)
+	require.Equal(t, "5432", val)
+
+	// The payload is served from the cache.
+	require.NoError(t, secretsManager.GetInto(context.Background(), &config))
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+
+	var wrongType struct {
+		Port string `json:"port"`
+	}
+	require.ErrorContains(t, secretsManager.GetInto(context.Background(), &wrongType), "failed to decode")
+}
+
+func TestSecretsManager_GetInto_WithStructTag(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"db_host":"db.internal","db_port":5432,"Region":"eu-west-1","replicas":[{"db_host":"replica.internal"}],"credentials":{"user_name":"admin"}}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithStructTag("mapstructure"),
+	)
+	require.NoError(t, err)
+
+	type endpoint struct {
+		Host string `mapstructure:"db_host"`
+		Port int    `mapstructure:"db_port"`
+	}
+	var config struct {
+		endpoint `mapstructure:",squash"`
+		Region   string
+		Replicas []endpoint `mapstructure:"replicas"`
+		Creds    *struct {
+			User string `mapstructure:"user_name" json:"user"`
+		} `mapstructure:"credentials"`
+	}
+	require.NoError(t, secretsManager.GetInto(context.Background(), &config))
+	require.Equal(t, "db.internal", config.Host)
+	require.Equal(t, 5432, config.Port)
+	require.Equal(t, "eu-west-1", config.Region)
+	require.Equal(t, []endpoint{{Host: "replica.internal"}}, config.Replicas)
+	require.Equal(t, "admin", config.Creds.User)
+}
+
+func TestSecretsManager_GetInto_WithStructTag_UnmatchedKeys(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"db_host":"db.internal","host":"other.internal","credentials":{"user_name":"admin","user":"intruder"}}`)
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithStructTag("mapstructure"),
+	)
+	require.NoError(t, err)
+
+	// Keys that match no tag are ignored, even if json would match them to a field ignoring case.
+	var config struct {
+		Host  string `mapstructure:"db_host"`
+		Creds struct {
+			User string `mapstructure:"user_name" json:"user"`
+		} `mapstructure:"credentials"`
+	}
+	require.NoError(t, secretsManager.GetInto(context.Background(), &config))
+	require.Equal(t, "db.internal", config.Host)
+	require.Equal(t, "admin", config.Creds.User)
+}
+
+func TestSecretsManager_GetJSON(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{
+		"OAUTH_CONFIG": {"client_id": "id", "scopes": ["read", "write"]},
+		"STRING_CONFIG": "{\"client_id\":\"string-id\",\"scopes\":[]}",
+		"app.v2": {"settings": {"client_id": "dotted-id"}},
+		"NOT_JSON": "plain"
+	}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	type oauthConfig struct {
+		ClientID string   `json:"client_id"`
+		Scopes   []string `json:"scopes"`
+	}
+	var config oauthConfig
+	require.NoError(t, secretsManager.GetJSON("OAUTH_CONFIG", &config))
+	require.Equal(t, oauthConfig{ClientID: "id", Scopes: []string{"read", "write"}}, config)
+
+	config = oauthConfig{}
+	require.NoError(t, secretsManager.GetJSON("STRING_CONFIG", &config))
+	require.Equal(t, oauthConfig{ClientID: "string-id", Scopes: []string{}}, config)
+
+	config = oauthConfig{}
+	require.NoError(t, secretsManager.GetJSON("app.v2.settings", &config))
+	require.Equal(t, "dotted-id", config.ClientID)
+
+	var scopes []string
+	require.NoError(t, secretsManager.GetJSON("OAUTH_CONFIG.scopes", &scopes))
+	require.Equal(t, []string{"read", "write"}, scopes)
+
+	err := secretsManager.GetJSON("NOT_JSON", &config)
+	require.ErrorContains(t, err, `key "NOT_JSON" in secret "test-secret" is not valid JSON`)
+
+	err = secretsManager.GetJSON("MISSING", &config)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+}
+
+func TestSecretsManager_GetJSON_NestedKeyIsCached(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"OAUTH_CONFIG":{"client_id":"id"}}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// Nested keys are resolved from the cached payload, so only the first call fetches the secret.
+	for range 5 {
+		var config struct {
+			ClientID string `json:"client_id"`
+		}
+		require.NoError(t, secretsManager.GetJSON("OAUTH_CONFIG", &config))
+		require.Equal(t, "id", config.ClientID)
+	}
+	var missing any
+	require.ErrorIs(t, secretsManager.GetJSON("MISSING", &missing), secretsmanagerWrapper.ErrSecretNotFound)
+	require.Equal(t, int32(1), atomic.LoadInt32(&smMock.callCount))
+}
@@ -0,0 +1,91 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// Staging labels that Secrets Manager attaches to the versions of a rotated secret.
+const (
+	VersionStageCurrent  = "AWSCURRENT"
+	VersionStagePending  = "AWSPENDING"
+	VersionStagePrevious = "AWSPREVIOUS"
+)
+
+// WithVersionStage reads the secret version with the given staging label, such as
+// VersionStagePrevious, instead of AWSCURRENT.
+func WithVersionStage(stage string) Option {
+	return func(s *SecretsManager) {
+		s.versionStage = stage
+	}
+}
+
+// WithVersionID pins the SecretsManager to the secret version with the given VersionId.
+// A pinned version never changes, so watchers will not see rotations.
+func WithVersionID(versionID string) Option {
+	return func(s *SecretsManager) {
+		s.pinnedVersionID = versionID
+	}
+}
+
+// GetVersion retrieves the value for the given key from the secret version with the given
+// staging label, e.g. VersionStagePending while a rotation is in progress. The version is read
+// from AWS on every call and not cached, so it never affects the values returned by Get.
+func (s *SecretsManager) GetVersion(ctx context.Context, key, stage string) (string, error) {
+	if err := s.checkAccess(ctx, key); err != nil {
+		return "", err
+	}
+	secret, err := s.fetchSecret(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId:     &s.secretName,
+		VersionStage: &stage,
+	})
+	if err != nil {
+		return "", err
+	}
+	values := secret.values
+	if s.resolveRefs {
+		values, err = s.resolveReferences(ctx, values)
+		if err != nil {
+			return "", err
+		}
+	}
+	val, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", key, ErrSecretNotFound)
+	}
+	return val, nil
+}
+
+// GetIfChanged is like GetWithContext, but only returns the value if the cached secret version
+// differs from lastSeenVersion, as with an HTTP conditional request. It always returns the
+// current version, to be passed as lastSeenVersion on the next call; pass an empty string the
+// first time. The version is that of the whole secret, so changed may be true for a key whose
+// value stayed the same in a new version.
+func (s *SecretsManager) GetIfChanged(ctx context.Context, key, lastSeenVersion string) (value, version string, changed bool, err error) {
+	// Read until the version is the same before and after the read, so that the value belongs to it
+	// even if a refresh runs concurrently.
+	for {
+		before := s.Version()
+		value, err = s.GetWithContext(ctx, key)
+		if err != nil {
+			return "", "", false, err
+		}
+		if version = s.Version(); version == before {
+			break
+		}
+	}
+	if version == lastSeenVersion {
+		return "", version, false, nil
+	}
+	return value, version, true, nil
+}
+
+// nonEmpty returns a pointer to v, or nil if v is empty, for optional AWS input fields.
+func nonEmpty(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,133 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mockVersionedSecretsManagerClient simulates a Secrets Manager client for a secret with several versions.
+type mockVersionedSecretsManagerClient struct {
+	// byStage and byID map staging labels and VersionIds to the JSON string of a version.
+	byStage map[string]string
+	byID    map[string]string
+
+	mu sync.Mutex
+	// inputs records the received requests.
+	inputs []*awsSecretsManager.GetSecretValueInput
+}
+
+// GetSecretValue simulates the AWS SDK GetSecretValue method.
+func (m *mockVersionedSecretsManagerClient) GetSecretValue(_ context.Context, input *awsSecretsManager.GetSecretValueInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
+	m.mu.Lock()
+	m.inputs = append(m.inputs, input)
+	m.mu.Unlock()
+
+	val := m.byStage[secretsmanagerWrapper.VersionStageCurrent]
+	if input.VersionId != nil {
+		val = m.byID[*input.VersionId]
+	} else if input.VersionStage != nil {
+		val = m.byStage[*input.VersionStage]
+	}
+	return &awsSecretsManager.GetSecretValueOutput{SecretString: aws.String(val)}, nil
+}
+
+func newVersionedClient() *mockVersionedSecretsManagerClient {
+	return &mockVersionedSecretsManagerClient{
+		byStage: map[string]string{
+			secretsmanagerWrapper.VersionStageCurrent:  `{"DB_PASSWORD":"current"}`,
+			secretsmanagerWrapper.VersionStagePrevious: `{"DB_PASSWORD":"previous"}`,
+			secretsmanagerWrapper.VersionStagePending:  `{"DB_PASSWORD":"pending"}`,
+		},
+		byID: map[string]string{"v1": `{"DB_PASSWORD":"v1"}`},
+	}
+}
+
+func TestSecretsManager_WithVersionStage(t *testing.T) {
+	smMock := newVersionedClient()
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithVersionStage(secretsmanagerWrapper.VersionStagePrevious),
+	)
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "previous", val)
+	require.Equal(t, secretsmanagerWrapper.VersionStagePrevious, aws.ToString(smMock.inputs[0].VersionStage))
+	require.Nil(t, smMock.inputs[0].VersionId)
+}
+
+func TestSecretsManager_WithVersionID(t *testing.T) {
+	smMock := newVersionedClient()
+	secretsManager, err := secretsmanagerWrapper.NewSecretsManager("us-test-1", "test-secret", "test-kms-key",
+		secretsmanagerWrapper.WithSecretsManagerClient(smMock),
+		secretsmanagerWrapper.WithKMSClient(&mockKMSClient{}),
+		secretsmanagerWrapper.WithVersionID("v1"),
+	)
+	require.NoError(t, err)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "v1", val)
+}
+
+func TestSecretsManager_GetVersion(t *testing.T) {
+	smMock := newVersionedClient()
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "current", val)
+
+	val, err = secretsManager.GetVersion(context.Background(), "DB_PASSWORD", secretsmanagerWrapper.VersionStagePending)
+	require.NoError(t, err)
+	require.Equal(t, "pending", val)
+
+	// Reading another version does not affect the cache.
+	val, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "current", val)
+
+	_, err = secretsManager.GetVersion(context.Background(), "MISSING", secretsmanagerWrapper.VersionStagePending)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+}
+
+func TestSecretsManager_GetIfChanged(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Hour)
+	ctx := context.Background()
+
+	val, version, changed, err := secretsManager.GetIfChanged(ctx, "DB_PASSWORD", "")
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, "password", val)
+	require.NotEmpty(t, version)
+
+	// The same version is not returned again.
+	val, next, changed, err := secretsManager.GetIfChanged(ctx, "DB_PASSWORD", version)
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Empty(t, val)
+	require.Equal(t, version, next)
+
+	// A new version is.
+	smMock.secretValue.Store(`{"DB_PASSWORD":"newPassword"}`)
+	require.NoError(t, secretsManager.Refresh(ctx))
+	val, next, changed, err = secretsManager.GetIfChanged(ctx, "DB_PASSWORD", version)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, "newPassword", val)
+	require.NotEqual(t, version, next)
+
+	_, _, _, err = secretsManager.GetIfChanged(ctx, "MISSING", version)
+	require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+}
@@ -0,0 +1,72 @@
+package secretsmanager
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// WatchAll starts a single background goroutine that polls the entire secret every interval and
+// calls callback with all keys whose values changed since the previous poll, including added keys.
+// Removed keys are reported with an empty value. Values are only decrypted when the secret changed.
+// ctx and opts work as for Watch.
+func (s *SecretsManager) WatchAll(ctx context.Context, interval time.Duration, callback func(changed map[string]string), opts ...WatchOption) *Watcher {
+	return s.watchKeys(ctx, nil, interval, callback, opts)
+}
+
+// WatchKeys is WatchAll, restricted to keys. Watching several keys this way polls the secret once
+// per interval, rather than once per key.
+func (s *SecretsManager) WatchKeys(ctx context.Context, keys []string, interval time.Duration, callback func(changed map[string]string), opts ...WatchOption) *Watcher {
+	if keys == nil {
+		keys = []string{}
+	}
+	return s.watchKeys(ctx, keys, interval, callback, opts)
+}
+
+// watchKeys implements WatchAll and WatchKeys; nil keys watches all keys.
+func (s *SecretsManager) watchKeys(ctx context.Context, keys []string, interval time.Duration, callback func(changed map[string]string), opts []WatchOption) *Watcher {
+	var include func(key string) bool
+	if keys != nil {
+		include = func(key string) bool { return slices.Contains(keys, key) }
+	}
+	var lastHash string
+	var lastValues map[string]string
+	return s.startWatcher(ctx, interval, opts, func(ctx context.Context) (string, error) {
+		if err := s.ensureFresh(ctx); err != nil {
+			return "", err
+		}
+		hash := s.ContentHash()
+		if hash == lastHash {
+			return "", nil
+		}
+		// Only the watched keys are read, so the access hook is not run for the others.
+		values, err := s.getAll(ctx, include)
+		if err != nil {
+			return "", err
+		}
+		if lastValues != nil {
+			if changed := diffValues(lastValues, values); len(changed) > 0 {
+				callback(changed)
+			}
+		}
+		lastHash, lastValues = hash, values
+		return "", nil
+	})
+}
+
+// diffValues returns the keys of next whose values differ from prev, and the keys of prev that
+// are missing from next, with an empty value.
+func diffValues(prev, next map[string]string) map[string]string {
+	changed := make(map[string]string)
+	for k, v := range next {
+		if old, ok := prev[k]; !ok || old != v {
+			changed[k] = v
+		}
+	}
+	for k := range prev {
+		if _, ok := next[k]; !ok {
+			changed[k] = ""
+		}
+	}
+	return changed
+}
@@ -0,0 +1,42 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_WatchAll(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"A":"a1","B":"b1","C":"c1"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, 5*time.Millisecond)
+
+	allCh := make(chan map[string]string, 1)
+	keysCh := make(chan map[string]string, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	secretsManager.WatchAll(ctx, 10*time.Millisecond, func(changed map[string]string) {
+		allCh <- changed
+	})
+	secretsManager.WatchKeys(ctx, []string{"A", "C"}, 10*time.Millisecond, func(changed map[string]string) {
+		keysCh <- changed
+	})
+
+	// Wait for the initial values to be polled.
+	time.Sleep(50 * time.Millisecond)
+	smMock.secretValue.Store(`{"A":"a2","B":"b1","D":"d1"}`)
+
+	for ch, want := range map[chan map[string]string]map[string]string{
+		allCh:  {"A": "a2", "C": "", "D": "d1"},
+		keysCh: {"A": "a2", "C": ""},
+	} {
+		select {
+		case changed := <-ch:
+			require.Equal(t, want, changed)
+		case <-time.After(500 * time.Millisecond):
+			t.Fatal("timeout waiting for watcher callback")
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package secretsmanager
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// watchOptions holds the settings of Watch.
+type watchOptions struct {
+	onError func(err error)
+}
+
+// WatchOption defines a functional option for configuring Watch.
+type WatchOption func(*watchOptions)
+
+// WatchOnError calls onError with every error the watcher runs into, such as a failed fetch,
+// so callers can tell that it does not see changes. The watcher keeps polling.
+func WatchOnError(onError func(err error)) WatchOption {
+	return func(o *watchOptions) {
+		o.onError = onError
+	}
+}
+
+// Watcher is a handle to a running watcher, returned by Watch, WatchAll and WatchKeys. It is safe
+// for concurrent use.
+type Watcher struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu            sync.Mutex
+	lastValue     string
+	lastErr       error
+	lastCheckedAt time.Time
+	// errorSinks are called with every error, e.g. by a WatcherGroup.
+	errorSinks []func(err error)
+}
+
+// Stop stops the watcher. It does not wait for a running callback to return.
+func (w *Watcher) Stop() {
+	w.cancel()
+}
+
+// IsRunning reports whether the watcher is still running. It returns false once the watcher was
+// stopped, or its context is done.
+func (w *Watcher) IsRunning() bool {
+	select {
+	case <-w.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// LastValue returns the value seen by the last successful poll of Watch. It is empty for WatchAll
+// and WatchKeys.
+func (w *Watcher) LastValue() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastValue
+}
+
+// LastError returns the error of the last poll, or nil if it succeeded.
+func (w *Watcher) LastError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+// LastCheckedAt returns when the last poll finished, or the zero time before the first one.
+func (w *Watcher) LastCheckedAt() time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastCheckedAt
+}
+
+// record stores the result of a poll, and passes an error to the error sinks.
+func (w *Watcher) record(val string, err error) {
+	w.mu.Lock()
+	if err == nil {
+		w.lastValue = val
+	}
+	w.lastErr = err
+	w.lastCheckedAt = time.Now()
+	sinks := w.errorSinks
+	w.mu.Unlock()
+	if err != nil {
+		for _, sink := range sinks {
+			sink(err)
+		}
+	}
+}
+
+// addErrorSink makes the watcher call sink with every error from now on.
+func (w *Watcher) addErrorSink(sink func(err error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.errorSinks = append(w.errorSinks, sink)
+}
+
+// startWatcher starts a goroutine that calls check right away and then every interval, until ctx
+// is done or the returned Watcher is stopped. Errors of check are reported as set by opts. With
+// WithLeaderCheck, followers skip the polls on each interval unless the secret has a new version,
+// but not those on pokes. A non-positive interval is reported as an ErrInvalidConfig error, and
+// the watcher does not run.
+func (s *SecretsManager) startWatcher(ctx context.Context, interval time.Duration, opts []WatchOption, check func(ctx context.Context) (string, error)) *Watcher {
+	var o watchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	w := &Watcher{cancel: cancel, done: make(chan struct{})}
+	if interval <= 0 {
+		err := fmt.Errorf("%w: watch interval must be positive, got %s", ErrInvalidConfig, interval)
+		s.log().Error(ctx, "failed to start watcher", "secret", s.secretName, "error", err)
+		w.record("", err)
+		if o.onError != nil {
+			o.onError(err)
+		}
+		cancel()
+		close(w.done)
+		return w
+	}
+	poke := s.registerWatcher()
+	go func() {
+		defer close(w.done)
+		defer cancel()
+		defer s.unregisterWatcher(poke)
+		// deletionWarned limits the deletion warning to once per failure streak.
+		deletionWarned := false
+		poll := func() {
+			val, err := check(ctx)
+			if ctx.Err() != nil {
+				return
+			}
+			w.record(val, err)
+			if err != nil {
+				if !deletionWarned {
+					deletionWarned = s.logScheduledForDeletion(ctx, err)
+				}
+				if o.onError != nil {
+					o.onError(err)
+				}
+				return
+			}
+			deletionWarned = false
+		}
+
+		poll()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if s.shouldPoll() {
+					poll()
+				} else {
+					// A new version pokes all watchers, including this one.
+					s.refreshIfNewVersion(ctx, interval)
+				}
+			case <-poke:
+				poll()
+			}
+		}
+	}()
+	return w
+}
+
+// registerWatcher returns a channel that receives a value when the watcher should poll right away.
+func (s *SecretsManager) registerWatcher() chan struct{} {
+	poke := make(chan struct{}, 1)
+	s.watchPokesLock.Lock()
+	defer s.watchPokesLock.Unlock()
+	if s.watchPokes == nil {
+		s.watchPokes = make(map[chan struct{}]struct{})
+	}
+	s.watchPokes[poke] = struct{}{}
+	return poke
+}
+
+// unregisterWatcher removes a channel returned by registerWatcher.
+func (s *SecretsManager) unregisterWatcher(poke chan struct{}) {
+	s.watchPokesLock.Lock()
+	defer s.watchPokesLock.Unlock()
+	delete(s.watchPokes, poke)
+}
+
+// pokeWatchers makes all running watchers poll right away.
+func (s *SecretsManager) pokeWatchers() {
+	s.watchPokesLock.Lock()
+	defer s.watchPokesLock.Unlock()
+	for poke := range s.watchPokes {
+		select {
+		case poke <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package secretsmanager
+
+import (
+	"sync"
+	"time"
+)
+
+// watcherGroupErrors is the capacity of the error channel of a WatcherGroup.
+const watcherGroupErrors = 16
+
+// WatcherError is an error of a watcher in a WatcherGroup.
+type WatcherError struct {
+	// Name is the name the watcher was added under.
+	Name string
+	Err  error
+}
+
+// Error implements error.
+func (e *WatcherError) Error() string {
+	return "watcher " + e.Name + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the watcher.
+func (e *WatcherError) Unwrap() error {
+	return e.Err
+}
+
+// WatcherStatus is the status of a watcher in a WatcherGroup, for health reporting. It holds no
+// secret values.
+type WatcherStatus struct {
+	Name          string
+	Running       bool
+	LastError     error
+	LastCheckedAt time.Time
+}
+
+// WatcherGroup manages many watchers as one unit, e.g. to stop them all on shutdown and to
+// report their health. It is safe for concurrent use.
+type WatcherGroup struct {
+	mu       sync.Mutex
+	names    []string
+	watchers []*Watcher
+	errs     chan *WatcherError
+}
+
+// NewWatcherGroup returns an empty WatcherGroup.
+func NewWatcherGroup() *WatcherGroup {
+	return &WatcherGroup{errs: make(chan *WatcherError, watcherGroupErrors)}
+}
+
+// Add adds the watcher w, as returned by Watch, WatchAll or WatchKeys, under name. Its errors are
+// sent to the Errors channel from now on.
+func (g *WatcherGroup) Add(name string, w *Watcher) {
+	g.mu.Lock()
+	g.names = append(g.names, name)
+	g.watchers = append(g.watchers, w)
+	g.mu.Unlock()
+	w.addErrorSink(func(err error) {
+		select {
+		case g.errs <- &WatcherError{Name: name, Err: err}:
+		default:
+			// Nobody is keeping up with the errors; LastError still reports the latest one.
+		}
+	})
+}
+
+// Errors returns the channel that receives the errors of all watchers in the group. Errors are
+// dropped while the channel is full, so a watcher never blocks on a slow reader.
+func (g *WatcherGroup) Errors() <-chan *WatcherError {
+	return g.errs
+}
+
+// Statuses returns the status of every watcher, in the order they were added.
+func (g *WatcherGroup) Statuses() []WatcherStatus {
+	names, watchers := g.snapshot()
+	statuses := make([]WatcherStatus, len(watchers))
+	for i, w := range watchers {
+		statuses[i] = WatcherStatus{
+			Name:          names[i],
+			Running:       w.IsRunning(),
+			LastError:     w.LastError(),
+			LastCheckedAt: w.LastCheckedAt(),
+		}
+	}
+	return statuses
+}
+
+// Stop stops all watchers. Call Wait to wait for them to exit.
+func (g *WatcherGroup) Stop() {
+	_, watchers := g.snapshot()
+	for _, w := range watchers {
+		w.Stop()
+	}
+}
+
+// Wait blocks until all watchers added so far exited, i.e. were stopped or their context is done,
+// including any callback they were running.
+func (g *WatcherGroup) Wait() {
+	_, watchers := g.snapshot()
+	for _, w := range watchers {
+		<-w.done
+	}
+}
+
+// snapshot returns the names and watchers of the group.
+func (g *WatcherGroup) snapshot() ([]string, []*Watcher) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.names...), append([]*Watcher(nil), g.watchers...)
+}
@@ -0,0 +1,58 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	secretsmanagerWrapper "github.com/janduursma/aws-secretsmanager-wrapper-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcherGroup(t *testing.T) {
+	smMock := &mockSecretsManagerClient{}
+	smMock.secretValue.Store(`{"DB_PASSWORD":"password"}`)
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Millisecond)
+	ctx := context.Background()
+
+	g := secretsmanagerWrapper.NewWatcherGroup()
+	g.Add("password", secretsManager.Watch(ctx, "DB_PASSWORD", 10*time.Millisecond, func(string) {}))
+	g.Add("missing", secretsManager.Watch(ctx, "MISSING", 10*time.Millisecond, func(string) {}))
+
+	// Errors of all watchers arrive on one channel, by name.
+	select {
+	case err := <-g.Errors():
+		require.Equal(t, "missing", err.Name)
+		require.ErrorIs(t, err, secretsmanagerWrapper.ErrSecretNotFound)
+	case <-time.After(time.Second):
+		t.Fatal("no error received")
+	}
+
+	require.Eventually(t, func() bool {
+		return !g.Statuses()[0].LastCheckedAt.IsZero()
+	}, time.Second, 5*time.Millisecond)
+	statuses := g.Statuses()
+	require.Len(t, statuses, 2)
+	require.Equal(t, "password", statuses[0].Name)
+	require.True(t, statuses[0].Running)
+	require.NoError(t, statuses[0].LastError)
+	require.Equal(t, "missing", statuses[1].Name)
+	require.True(t, errors.Is(statuses[1].LastError, secretsmanagerWrapper.ErrSecretNotFound))
+
+	// Stop and Wait shut all watchers down.
+	g.Stop()
+	done := make(chan struct{})
+	go func() {
+		g.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watchers did not exit")
+	}
+	for _, status := range g.Statuses() {
+		require.False(t, status.Running)
+	}
+}
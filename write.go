@@ -0,0 +1,118 @@
+package secretsmanager
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// Put sets key to value in the secret and writes the result as a new version, see PutAll.
+func (s *SecretsManager) Put(ctx context.Context, key, value string) error {
+	return s.PutAll(ctx, map[string]string{key: value})
+}
+
+// PutAll merges values into the current JSON object of the secret and writes the result as a new
+// version, which becomes AWSCURRENT. The values are written as JSON strings under the given
+// top-level keys; all other keys are kept as they are, including nested objects and numbers.
+// The local cache is purged afterwards, so the next read returns the new version. With
+// WithCanaryCheck, the new version is read back and checked instead, and an error is returned if
+// it fails; the write is not undone.
+// The merge is a read followed by a write, so concurrent writers may overwrite each other's keys.
+// It requires a Secrets Manager client that implements WriterClient.
+func (s *SecretsManager) PutAll(ctx context.Context, values map[string]string) error {
+	writer, ok := s.secretsManagerAPI().(WriterClient)
+	if !ok {
+		return fmt.Errorf("put %s: Secrets Manager client cannot write secrets", s.secretName)
+	}
+
+	secret, err := s.fetchSecret(ctx, &secretsmanager.GetSecretValueInput{SecretId: &s.secretName})
+	if err != nil {
+		return err
+	}
+	if secret.binary != nil {
+		return fmt.Errorf("put %s: secret does not hold a JSON object", s.secretName)
+	}
+	fields, err := secret.fields()
+	if err != nil {
+		return fmt.Errorf("put %s: secret does not hold a JSON object", s.secretName)
+	}
+
+	for k, v := range values {
+		if fields[k], err = json.Marshal(v); err != nil {
+			return err
+		}
+	}
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	return s.putSecretValue(ctx, writer, &secretsmanager.PutSecretValueInput{
+		SecretId:     &s.secretName,
+		SecretString: aws.String(string(payload)),
+	})
+}
+
+// PutBinary writes data as the SecretBinary payload of a new version of the secret, which becomes
+// AWSCURRENT, e.g. for certificates and keystores. With WithBinarySecrets(true), data is
+// base64-encoded first, so GetBinary returns it as it was. Versioning, cache purging and
+// WithCanaryCheck work as for PutAll. It requires a Secrets Manager client that implements
+// WriterClient.
+func (s *SecretsManager) PutBinary(ctx context.Context, data []byte) error {
+	writer, ok := s.secretsManagerAPI().(WriterClient)
+	if !ok {
+		return fmt.Errorf("put %s: Secrets Manager client cannot write secrets", s.secretName)
+	}
+	if s.base64Binary {
+		data = base64.StdEncoding.AppendEncode(nil, data)
+	}
+	return s.putSecretValue(ctx, writer, &secretsmanager.PutSecretValueInput{
+		SecretId:     &s.secretName,
+		SecretBinary: data,
+	})
+}
+
+// putSecretValue writes a new version of the secret with input, and then purges the cache, or
+// with WithCanaryCheck, reads the new version back.
+func (s *SecretsManager) putSecretValue(ctx context.Context, writer WriterClient, input *secretsmanager.PutSecretValueInput) error {
+	out, err := writer.PutSecretValue(ctx, input)
+	if err != nil {
+		return fmt.Errorf("put %s: %w", s.secretName, err)
+	}
+	if s.canaryCheck != nil {
+		// Read the new version back, so it passes the canary check before it is served. A refresh
+		// that was already in flight may have read the previous version, so refresh once more then.
+		for range 2 {
+			if err := s.refresh(ctx); err != nil {
+				return err
+			}
+			if s.Version() == aws.ToString(out.VersionId) {
+				break
+			}
+		}
+		return nil
+	}
+	s.purgeCache()
+	return nil
+}
+
+// fields decodes the top-level fields of the secret's JSON object payload, keeping their values
+// as raw JSON, so they can be written back without flattening nested objects.
+func (f *fetchedSecret) fields() (map[string]json.RawMessage, error) {
+	payload := []byte(f.secretString)
+	if f.binary != nil {
+		payload, _ = sanitizePayload(f.binary)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		return nil, fmt.Errorf("payload is not a JSON object")
+	}
+	return fields, nil
+}
@@ -0,0 +1,136 @@
+package secretsmanager_test
+
+import (
+	"context"
+	"encoding/base64"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsSecretsManager "github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretsManager_PutAll(t *testing.T) {
+	smMock := &mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret": `{"DB_USER":"admin","DB_PASSWORD":"oldPassword"}`,
+	}}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	val, err := secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "oldPassword", val)
+
+	// New values are merged into the existing keys.
+	require.NoError(t, secretsManager.PutAll(context.Background(), map[string]string{
+		"DB_PASSWORD": "newPassword",
+		"DB_HOST":     "db.internal",
+	}))
+	require.JSONEq(t, `{"DB_USER":"admin","DB_PASSWORD":"newPassword","DB_HOST":"db.internal"}`, smMock.secret("test-secret"))
+
+	// The cache is purged, so the new value is served right away despite the long TTL.
+	val, err = secretsManager.Get("DB_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "newPassword", val)
+
+	require.NoError(t, secretsManager.Put(context.Background(), "DB_USER", "root"))
+	val, err = secretsManager.Get("DB_USER")
+	require.NoError(t, err)
+	require.Equal(t, "root", val)
+}
+
+func TestSecretsManager_PutAll_NestedSecret(t *testing.T) {
+	smMock := &mockSecretsByIDClient{secrets: map[string]string{
+		"test-secret": `{"db":{"user":"admin","port":5432},"tags":["a","b"],"enabled":true}`,
+	}}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+
+	// Only the given top-level key is written; nested objects, arrays and numbers are kept as they are.
+	require.NoError(t, secretsManager.Put(context.Background(), "DB_PASSWORD", "password"))
+	require.JSONEq(t, `{"db":{"user":"admin","port":5432},"tags":["a","b"],"enabled":true,"DB_PASSWORD":"password"}`,
+		smMock.secret("test-secret"))
+
+	val, err := secretsManager.Get("db.port")
+	require.NoError(t, err)
+	require.Equal(t, "5432", val)
+}
+
+func TestSecretsManager_Put_Errors(t *testing.T) {
+	// Secrets holding a JSON array cannot be merged into.
+	smMock := &mockSecretsByIDClient{secrets: map[string]string{"test-secret": `[{"name":"a"}]`}}
+	secretsManager := newSecretsManagerForTest(t, smMock, &mockKMSClient{}, time.Minute)
+	require.ErrorContains(t, secretsManager.Put(context.Background(), "name", "b"), "does not hold a JSON object")
+
+	// Clients that cannot write are rejected.
+	readOnly := &mockSecretsManagerClient{}
+	readOnly.secretValue.Store(`{}`)
+	secretsManager = newSecretsManagerForTest(t, readOnly, &mockKMSClient{}, time.Minute)
+	require.ErrorContains(t, secretsManager.Put(context.Background(), "DB_USER", "root"), "cannot write secrets")
+}
+
+// mockBinarySecretClient simulates a Secrets Manager client holding one binary secret, which can
+// also be written.
+type mockBinarySecretClient struct {
+	mu      sync.Mutex
+	binary  []byte
+	version int
+}
+
+func (m *mockBinarySecretClient) GetSecretValue(_ context.Context, _ *awsSecretsManager.GetSecretValueInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.GetSecretValueOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &awsSecretsManager.GetSecretValueOutput{
+		SecretBinary: m.binary,
+		VersionId:    aws.String(strconv.Itoa(m.version)),
+	}, nil
+}
+
+func (m *mockBinarySecretClient) PutSecretValue(_ context.Context, input *awsSecretsManager.PutSecretValueInput, _ ...func(*awsSecretsManager.Options)) (*awsSecretsManager.PutSecretValueOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.binary = input.SecretBinary
+	m.version++
+	return &awsSecretsManager.PutSecretValueOutput{
+		Name:      input.SecretId,
+		VersionId: aws.String(strconv.Itoa(m.version)),
+	}, nil
+}
+
+func TestSecretsManager_PutBinary(t *testing.T) {
+	smMock := &mockBinarySecretClient{binary: []byte{0x00, 0x01}, version: 1}
+	secretsManager := newBinarySecretsManagerForTest(t, smMock, false)
+
+	data, err := secretsManager.GetBinary(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x00, 0x01}, data)
+	require.Equal(t, "1", secretsManager.Version())
+
+	// The new version is written, and read on the next call instead of the cached one.
+	keystore := []byte{0xfe, 0xed, 0xfe, 0xed}
+	require.NoError(t, secretsManager.PutBinary(context.Background(), keystore))
+	require.Equal(t, keystore, smMock.binary)
+	data, err = secretsManager.GetBinary(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, keystore, data)
+	require.Equal(t, "2", secretsManager.Version())
+}
+
+func TestSecretsManager_PutBinary_Base64(t *testing.T) {
+	smMock := &mockBinarySecretClient{binary: []byte(base64.StdEncoding.EncodeToString([]byte("old"))), version: 1}
+	secretsManager := newBinarySecretsManagerForTest(t, smMock, true)
+
+	// With base64-encoded binary secrets, the data is encoded on the way in.
+	require.NoError(t, secretsManager.PutBinary(context.Background(), []byte("new")))
+	require.Equal(t, base64.StdEncoding.EncodeToString([]byte("new")), string(smMock.binary))
+	data, err := secretsManager.GetBinary(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []byte("new"), data)
+
+	// Clients that cannot write are rejected.
+	readOnly := &mockSecretsManagerClient{}
+	readOnly.secretValue.Store(`{}`)
+	secretsManager = newSecretsManagerForTest(t, readOnly, &mockKMSClient{}, time.Minute)
+	require.ErrorContains(t, secretsManager.PutBinary(context.Background(), []byte("new")), "cannot write secrets")
+}